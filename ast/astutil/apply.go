@@ -0,0 +1,338 @@
+// Package astutil contains utilities for working with the syntax tree of SPL programs.
+package astutil
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/Minnozz/gospl/ast"
+)
+
+// ApplyFunc is called by Apply for every node.  See Apply for the meaning of the return value.
+type ApplyFunc func(*Cursor) bool
+
+// Apply traverses the tree rooted at root in depth-first order, like ast.Walk, and calls pre and post for every non-nil node
+// before and after its children.  pre and post may be nil.
+//
+// If pre returns false, the children of the node and the call to post are skipped.  If post returns false, the traversal is
+// aborted.
+//
+// The Cursor passed to pre and post can be used to modify the tree in place.  Nodes that are inserted by pre or post are not
+// traversed.  Replacing the node in pre traverses the children of the new node instead.  Apply returns the (possibly
+// replaced) root.
+//
+// ast.File.Imports is not traversed, because its declarations are also in ast.File.Declarations.  It is not updated when
+// declarations are modified.
+func Apply(root ast.Node, pre, post ApplyFunc) (result ast.Node) {
+	parent := &struct{ ast.Node }{root}
+	defer func() {
+		if r := recover(); r != nil && r != abort {
+			panic(r)
+		}
+		result = parent.Node
+	}()
+
+	a := &application{
+		pre:  pre,
+		post: post,
+	}
+	a.apply(parent, "Node", nil, root)
+
+	return
+}
+
+var abort = new(int) // Sentinel value to abort a traversal
+
+// Cursor describes a node encountered during Apply, and the field of its parent node that contains it.
+type Cursor struct {
+	parent ast.Node
+	name   string
+	iter   *iterator // Set if the node is part of a slice
+	node   ast.Node
+}
+
+// Node returns the current node
+func (c *Cursor) Node() ast.Node { return c.node }
+
+// Parent returns the parent of the current node
+func (c *Cursor) Parent() ast.Node { return c.parent }
+
+// Name returns the name of the field of the parent node that contains the current node.  If the parent node is the root
+// passed to Apply, the name is "Node".
+func (c *Cursor) Name() string { return c.name }
+
+// Index returns the index of the current node in the slice c.Parent().c.Name(), or -1 if the field is not a slice.
+func (c *Cursor) Index() int {
+	if c.iter != nil {
+		return c.iter.index
+	}
+	return -1
+}
+
+func (c *Cursor) field() reflect.Value {
+	return reflect.Indirect(reflect.ValueOf(c.parent)).FieldByName(c.name)
+}
+
+// Replace replaces the current node by n.  It panics if n cannot be stored in the field of the parent node.
+func (c *Cursor) Replace(n ast.Node) {
+	v := c.field()
+	if i := c.Index(); i >= 0 {
+		v = v.Index(i)
+	}
+	v.Set(value(n, v.Type()))
+	c.node = n
+}
+
+// Delete removes the current node from the slice that contains it.  It panics if the current node is not part of a slice.
+func (c *Cursor) Delete() {
+	i := c.Index()
+	if i < 0 {
+		panic("Delete of node that is not in a slice")
+	}
+
+	v := c.field()
+	l := v.Len()
+	reflect.Copy(v.Slice(i, l), v.Slice(i+1, l))
+	v.Index(l - 1).Set(reflect.Zero(v.Type().Elem()))
+	v.SetLen(l - 1)
+	c.iter.step--
+}
+
+// InsertAfter inserts n after the current node in the slice that contains it.  It panics if the current node is not part of a
+// slice.
+func (c *Cursor) InsertAfter(n ast.Node) {
+	i := c.Index()
+	if i < 0 {
+		panic("InsertAfter of node that is not in a slice")
+	}
+
+	v := c.field()
+	l := v.Len()
+	v.Set(reflect.Append(v, reflect.Zero(v.Type().Elem())))
+	reflect.Copy(v.Slice(i+2, l+1), v.Slice(i+1, l))
+	v.Index(i + 1).Set(value(n, v.Type().Elem()))
+	c.iter.step++
+}
+
+// InsertBefore inserts n before the current node in the slice that contains it.  It panics if the current node is not part of
+// a slice.
+func (c *Cursor) InsertBefore(n ast.Node) {
+	i := c.Index()
+	if i < 0 {
+		panic("InsertBefore of node that is not in a slice")
+	}
+
+	v := c.field()
+	l := v.Len()
+	v.Set(reflect.Append(v, reflect.Zero(v.Type().Elem())))
+	reflect.Copy(v.Slice(i+1, l+1), v.Slice(i, l))
+	v.Index(i).Set(value(n, v.Type().Elem()))
+	c.iter.index++
+}
+
+// value returns n as a value that can be stored in a field or slice element of type t
+func value(n ast.Node, t reflect.Type) reflect.Value {
+	if n == nil {
+		return reflect.Zero(t)
+	}
+	v := reflect.ValueOf(n)
+	if !v.Type().AssignableTo(t) {
+		panic(fmt.Sprintf("cannot store %T in a field of type %v", n, t))
+	}
+	return v
+}
+
+type iterator struct {
+	index, step int
+}
+
+type application struct {
+	pre, post ApplyFunc
+	cursor    Cursor
+}
+
+func (a *application) apply(parent ast.Node, name string, iter *iterator, n ast.Node) {
+	// Skip nil nodes, including typed nil pointers
+	if v := reflect.ValueOf(n); !v.IsValid() || v.Kind() == reflect.Ptr && v.IsNil() {
+		return
+	}
+
+	saved := a.cursor
+	a.cursor.parent = parent
+	a.cursor.name = name
+	a.cursor.iter = iter
+	a.cursor.node = n
+
+	if a.pre != nil && !a.pre(&a.cursor) {
+		a.cursor = saved
+		return
+	}
+
+	// Continue with the replacement, if any
+	n = a.cursor.node
+	if v := reflect.ValueOf(n); !v.IsValid() || v.Kind() == reflect.Ptr && v.IsNil() {
+		a.cursor = saved
+		return
+	}
+
+	// Apply to node children (if any)
+	switch n := n.(type) {
+	case *ast.File:
+		a.applyList(n, "Declarations")
+		a.applyList(n, "Comments")
+	case *ast.CommentGroup:
+		a.applyList(n, "List")
+	case *ast.ImportDeclaration:
+		a.apply(n, "Doc", nil, n.Doc)
+		a.apply(n, "Path", nil, n.Path)
+	case *ast.FixityDeclaration:
+		a.apply(n, "Doc", nil, n.Doc)
+		a.apply(n, "Precedence", nil, n.Precedence)
+		a.apply(n, "Operator", nil, n.Operator)
+		a.apply(n, "Function", nil, n.Function)
+	case *ast.VariableDeclaration:
+		a.apply(n, "Doc", nil, n.Doc)
+		a.apply(n, "Type", nil, n.Type)
+		a.apply(n, "Name", nil, n.Name)
+		a.apply(n, "Pattern", nil, n.Pattern)
+		a.apply(n, "Initializer", nil, n.Initializer)
+	case *ast.FunctionDeclaration:
+		a.apply(n, "Doc", nil, n.Doc)
+		a.apply(n, "Context", nil, n.Context)
+		a.apply(n, "ReturnType", nil, n.ReturnType)
+		a.apply(n, "Name", nil, n.Name)
+		a.apply(n, "Parameters", nil, n.Parameters)
+		a.applyList(n, "Variables")
+		a.applyList(n, "Statements")
+	case *ast.FunctionParameters:
+		a.applyList(n, "Parameters")
+	case *ast.FunctionParameter:
+		a.apply(n, "Type", nil, n.Type)
+		a.apply(n, "Name", nil, n.Name)
+	case *ast.RecordDeclaration:
+		a.apply(n, "Doc", nil, n.Doc)
+		a.apply(n, "Name", nil, n.Name)
+		a.applyList(n, "Fields")
+	case *ast.RecordField:
+		a.apply(n, "Type", nil, n.Type)
+		a.apply(n, "Name", nil, n.Name)
+	case *ast.TypeDeclaration:
+		a.apply(n, "Doc", nil, n.Doc)
+		a.apply(n, "Name", nil, n.Name)
+		a.applyList(n, "TypeParameters")
+		a.apply(n, "Type", nil, n.Type)
+	case *ast.DataDeclaration:
+		a.apply(n, "Doc", nil, n.Doc)
+		a.apply(n, "Name", nil, n.Name)
+		a.applyList(n, "TypeParameters")
+		a.applyList(n, "Constructors")
+	case *ast.DataConstructor:
+		a.apply(n, "Name", nil, n.Name)
+		a.applyList(n, "Fields")
+	case *ast.UnaryExpression:
+		a.apply(n, "Operand", nil, n.Operand)
+	case *ast.BinaryExpression:
+		a.apply(n, "Left", nil, n.Left)
+		a.apply(n, "Right", nil, n.Right)
+	case *ast.FunctionCallExpression:
+		a.apply(n, "Function", nil, n.Function)
+		a.applyList(n, "Arguments")
+	case *ast.LambdaExpression:
+		a.applyList(n, "Parameters")
+		a.apply(n, "Body", nil, n.Body)
+	case *ast.ParenthesizedExpression:
+		a.apply(n, "Expression", nil, n.Expression)
+	case *ast.TupleExpression:
+		a.applyList(n, "Elements")
+	case *ast.ListExpression:
+		a.applyList(n, "Elements")
+	case *ast.RangeExpression:
+		a.apply(n, "Low", nil, n.Low)
+		a.apply(n, "High", nil, n.High)
+	case *ast.ComprehensionExpression:
+		a.apply(n, "Value", nil, n.Value)
+		a.applyList(n, "Qualifiers")
+	case *ast.ComprehensionGenerator:
+		a.apply(n, "Pattern", nil, n.Pattern)
+		a.apply(n, "List", nil, n.List)
+	case *ast.RecordExpression:
+		a.apply(n, "Name", nil, n.Name)
+		a.applyList(n, "Values")
+	case *ast.FieldExpression:
+		a.apply(n, "Expression", nil, n.Expression)
+		a.apply(n, "Field", nil, n.Field)
+	case *ast.BlockStatement:
+		a.applyList(n, "List")
+	case *ast.ReturnStatement:
+		a.apply(n, "Value", nil, n.Value)
+	case *ast.IfStatement:
+		a.apply(n, "Condition", nil, n.Condition)
+		a.apply(n, "Body", nil, n.Body)
+		a.apply(n, "Else", nil, n.Else)
+	case *ast.WhileStatement:
+		a.apply(n, "Condition", nil, n.Condition)
+		a.apply(n, "Body", nil, n.Body)
+	case *ast.ForStatement:
+		a.apply(n, "Variable", nil, n.Variable)
+		a.apply(n, "Value", nil, n.Value)
+		a.apply(n, "High", nil, n.High)
+		a.apply(n, "Body", nil, n.Body)
+	case *ast.MatchStatement:
+		a.apply(n, "Value", nil, n.Value)
+		a.applyList(n, "Cases")
+	case *ast.MatchCase:
+		a.apply(n, "Pattern", nil, n.Pattern)
+		a.apply(n, "Body", nil, n.Body)
+	case *ast.AssignmentStatement:
+		a.apply(n, "Target", nil, n.Target)
+		a.apply(n, "Value", nil, n.Value)
+	case *ast.IncrementStatement:
+		a.apply(n, "Target", nil, n.Target)
+	case *ast.FunctionCallStatement:
+		a.apply(n, "FunctionCall", nil, n.FunctionCall)
+	case *ast.NamedType:
+		a.apply(n, "Name", nil, n.Name)
+		a.applyList(n, "Arguments")
+	case *ast.BasicType:
+		a.apply(n, "Name", nil, n.Name)
+	case *ast.TypeVariable:
+		a.apply(n, "Name", nil, n.Name)
+	case *ast.ParenthesizedType:
+		a.apply(n, "Type", nil, n.Type)
+	case *ast.TypeContext:
+		a.applyList(n, "Constraints")
+	case *ast.TupleType:
+		a.applyList(n, "Elements")
+	case *ast.FunctionType:
+		a.applyList(n, "Parameters")
+		a.apply(n, "Result", nil, n.Result)
+	case *ast.ListType:
+		a.apply(n, "ElementType", nil, n.ElementType)
+	}
+
+	if a.post != nil && !a.post(&a.cursor) {
+		panic(abort)
+	}
+
+	a.cursor = saved
+}
+
+// applyList applies to every node in the slice parent.name.  The slice may be modified through the cursor in the meantime.
+func (a *application) applyList(parent ast.Node, name string) {
+	iter := iterator{}
+	for {
+		v := reflect.Indirect(reflect.ValueOf(parent)).FieldByName(name)
+		if iter.index >= v.Len() {
+			break
+		}
+
+		var n ast.Node
+		if e := v.Index(iter.index); e.IsValid() && !e.IsNil() {
+			n = e.Interface().(ast.Node)
+		}
+
+		iter.step = 1
+		a.apply(parent, name, &iter, n)
+		iter.index += iter.step
+	}
+}
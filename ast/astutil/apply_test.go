@@ -0,0 +1,234 @@
+package astutil
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Minnozz/gospl/ast"
+	"github.com/Minnozz/gospl/parser"
+	"github.com/Minnozz/gospl/token"
+)
+
+const testSource = `
+Int
+main(Int n) {
+	Int x = n + 1;
+
+	print(x);
+	if(x > 0) {
+		print(n);
+		x = x - 1;
+	}
+	return f(x, n);
+}
+`
+
+func parseTestSource(t *testing.T, src string) *ast.File {
+	fileInfo := &token.FileInfo{
+		Filename: "test.spl",
+	}
+
+	p := &parser.Parser{}
+	p.Init(fileInfo, []byte(src))
+	file := p.Parse()
+	for _, err := range p.Errors {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func printCall(name string) *ast.FunctionCallStatement {
+	return &ast.FunctionCallStatement{
+		FunctionCall: &ast.FunctionCallExpression{
+			Function: &ast.Identifier{
+				Name: "print",
+			},
+			Arguments: []ast.Expression{
+				&ast.Identifier{
+					Name: name,
+				},
+			},
+		},
+	}
+}
+
+func isPrint(n ast.Node) bool {
+	stmt, ok := n.(*ast.FunctionCallStatement)
+	if !ok {
+		return false
+	}
+	ident, ok := stmt.FunctionCall.Function.(*ast.Identifier)
+	return ok && ident.Name == "print"
+}
+
+func TestApplyReplace(t *testing.T) {
+	file := parseTestSource(t, testSource)
+
+	Apply(file, func(c *Cursor) bool {
+		if ident, ok := c.Node().(*ast.Identifier); ok && ident.Name == "n" {
+			c.Replace(&ast.Identifier{
+				Name: "m",
+			})
+		}
+		return true
+	}, nil)
+
+	expected := `Int main(Int m) {
+	Int x = m + 1;
+
+	print(x);
+	if(x > 0) {
+		print(m);
+		x = x - 1;
+	}
+	return f(x, m);
+}`
+	if got := ast.PrintSource(file); got != expected {
+		t.Errorf("Got:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestApplyDeleteAndInsert(t *testing.T) {
+	file := parseTestSource(t, testSource)
+
+	Apply(file, nil, func(c *Cursor) bool {
+		switch n := c.Node().(type) {
+		case *ast.FunctionCallStatement:
+			if isPrint(n) {
+				c.Delete()
+			}
+		case *ast.AssignmentStatement:
+			c.InsertBefore(printCall("before"))
+			c.InsertAfter(printCall("after"))
+		case *ast.ReturnStatement:
+			// Inserted nodes are not traversed, so these are not deleted
+			c.InsertBefore(printCall("x"))
+			c.InsertAfter(printCall("unreachable"))
+		}
+		return true
+	})
+
+	expected := `Int main(Int n) {
+	Int x = n + 1;
+
+	if(x > 0) {
+		print(before);
+		x = x - 1;
+		print(after);
+	}
+	print(x);
+	return f(x, n);
+	print(unreachable);
+}`
+	if got := ast.PrintSource(file); got != expected {
+		t.Errorf("Got:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestApplyCursor(t *testing.T) {
+	file := parseTestSource(t, testSource)
+
+	var got []string
+	Apply(file, func(c *Cursor) bool {
+		if _, ok := c.Node().(*ast.Identifier); ok {
+			got = append(got, fmt.Sprintf("%T.%s[%d]", c.Parent(), c.Name(), c.Index()))
+		}
+		// Skip the body of the if statement
+		_, ok := c.Node().(*ast.BlockStatement)
+		return !ok
+	}, nil)
+
+	expected := []string{
+		"*ast.BasicType.Name[-1]",
+		"*ast.FunctionDeclaration.Name[-1]",
+		"*ast.BasicType.Name[-1]",
+		"*ast.FunctionParameter.Name[-1]",
+		"*ast.BasicType.Name[-1]",
+		"*ast.VariableDeclaration.Name[-1]",
+		"*ast.BinaryExpression.Left[-1]",
+		"*ast.FunctionCallExpression.Function[-1]",
+		"*ast.FunctionCallExpression.Arguments[0]",
+		"*ast.BinaryExpression.Left[-1]",
+		"*ast.FunctionCallExpression.Function[-1]",
+		"*ast.FunctionCallExpression.Arguments[0]",
+		"*ast.FunctionCallExpression.Arguments[1]",
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Got:\n%s\nexpected:\n%s", strings.Join(got, "\n"), strings.Join(expected, "\n"))
+	}
+}
+
+func TestApplyAbort(t *testing.T) {
+	file := parseTestSource(t, testSource)
+
+	count := 0
+	result := Apply(file, nil, func(c *Cursor) bool {
+		if _, ok := c.Node().(*ast.Identifier); ok {
+			count++
+		}
+		return count < 3
+	})
+
+	if count != 3 {
+		t.Errorf("Traversal was not aborted after 3 identifiers, got %d", count)
+	}
+	if result != file {
+		t.Errorf("Apply did not return the root")
+	}
+}
+
+func TestApplyReplaceRoot(t *testing.T) {
+	file := parseTestSource(t, testSource)
+	replacement := &ast.File{}
+
+	result := Apply(file, func(c *Cursor) bool {
+		if c.Node() == file {
+			c.Replace(replacement)
+		}
+		return true
+	}, nil)
+
+	if result != replacement {
+		t.Errorf("Apply did not return the replaced root")
+	}
+}
+
+// Apply must visit the same nodes in the same order as ast.Walk
+func TestApplyMatchesWalk(t *testing.T) {
+	files, err := filepath.Glob("../../testdata/valid/*.spl")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("Error reading test directory: %v", err)
+	}
+
+	for _, filename := range files {
+		src, err := ioutil.ReadFile(filename)
+		if err != nil {
+			t.Fatalf("Error reading test %s: %v", filename, err)
+		}
+		file := parseTestSource(t, string(src))
+
+		var walked, applied []ast.Node
+		ast.Inspect(file, func(n ast.Node) bool {
+			walked = append(walked, n)
+			return true
+		})
+		Apply(file, func(c *Cursor) bool {
+			applied = append(applied, c.Node())
+			return true
+		}, nil)
+
+		if len(walked) != len(applied) {
+			t.Errorf("%s: Walk visited %d nodes, Apply %d", filename, len(walked), len(applied))
+			continue
+		}
+		for i := range walked {
+			if walked[i] != applied[i] {
+				t.Errorf("%s: node %d is %T in Walk, %T in Apply", filename, i, walked[i], applied[i])
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package astutil
+
+import (
+	"github.com/Minnozz/gospl/ast"
+	"github.com/Minnozz/gospl/token"
+)
+
+// PathEnclosingInterval returns the path from the innermost node that encloses the interval [start, end) up to root.  The
+// first element of path is the innermost node, and the last element is root.  If start == end, the interval is the single
+// position start.
+//
+// A position in whitespace between nodes belongs to the node around them; a position in a comment belongs to the
+// *ast.Comment, whose parents are its *ast.CommentGroup and the file.  If the interval is not enclosed by any node in the
+// file, the path only contains root.
+//
+// exact reports whether the range of the innermost node is exactly [start, end).
+func PathEnclosingInterval(root *ast.File, start, end token.Pos) (path []ast.Node, exact bool) {
+	if end < start {
+		start, end = end, start
+	}
+
+	var n ast.Node = root
+	for {
+		path = append(path, n)
+
+		// Descend into the narrowest child that encloses the interval
+		var next ast.Node
+		for _, child := range childrenOf(n) {
+			if encloses(child, start, end) && (next == nil || child.End()-child.Pos() < next.End()-next.Pos()) {
+				next = child
+			}
+		}
+		if next == nil {
+			break
+		}
+		n = next
+	}
+
+	// Innermost node first
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+
+	return path, path[0].Pos() == start && path[0].End() == end
+}
+
+// NodeAt returns the innermost node at pos, or root if there is none
+func NodeAt(root *ast.File, pos token.Pos) ast.Node {
+	path, _ := PathEnclosingInterval(root, pos, pos)
+	return path[0]
+}
+
+// childrenOf returns the direct children of n, in the order of ast.Walk
+func childrenOf(n ast.Node) []ast.Node {
+	var children []ast.Node
+	ast.Inspect(n, func(child ast.Node) bool {
+		if child == n {
+			return true
+		}
+		children = append(children, child)
+		return false
+	})
+	return children
+}
+
+func encloses(n ast.Node, start, end token.Pos) bool {
+	if start == end {
+		return n.Pos() <= start && start < n.End()
+	}
+	return n.Pos() <= start && end <= n.End()
+}
@@ -0,0 +1,129 @@
+package astutil
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Minnozz/gospl/ast"
+	"github.com/Minnozz/gospl/parser"
+	"github.com/Minnozz/gospl/token"
+)
+
+// TestPathEnclosingInterval checks the path of every byte of the valid test programs
+func TestPathEnclosingInterval(t *testing.T) {
+	files, err := filepath.Glob("../../testdata/valid/*.spl")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("Error reading test directory: %v", err)
+	}
+
+	for _, filename := range files {
+		src, err := ioutil.ReadFile(filename)
+		if err != nil {
+			t.Fatalf("Error reading test %s: %v", filename, err)
+		}
+		fileInfo := &token.FileInfo{
+			Filename: filepath.Base(filename),
+		}
+		p := &parser.Parser{}
+		p.Init(fileInfo, src)
+		file := p.Parse()
+
+		for offset, ch := range src {
+			pos := fileInfo.Pos(offset)
+			path, _ := PathEnclosingInterval(file, pos, pos)
+			if !checkPath(t, fileInfo, path, file, pos) {
+				break
+			}
+
+			inner := path[0]
+			if _, ok := inner.(*ast.File); ok && ch != ' ' && ch != '\t' && ch != '\n' && ch != '\r' {
+				t.Errorf("%v: %q is not in any node", fileInfo.Position(pos), ch)
+			}
+			if NodeAt(file, pos) != inner {
+				t.Errorf("%v: NodeAt is not the innermost node of the path", fileInfo.Position(pos))
+			}
+		}
+
+		// The interval of every node gives that node, or a child with the same interval
+		ast.Inspect(file, func(n ast.Node) bool {
+			path, exact := PathEnclosingInterval(file, n.Pos(), n.End())
+			if !exact || path[0].Pos() != n.Pos() || path[0].End() != n.End() {
+				t.Errorf("%v: interval of %T gives %T (exact: %v)", fileInfo.Position(n.Pos()), n, path[0], exact)
+			}
+			return true
+		})
+	}
+}
+
+// checkPath checks that path goes from a node that encloses pos, and none of its children do, to file
+func checkPath(t *testing.T, fileInfo *token.FileInfo, path []ast.Node, file *ast.File, pos token.Pos) bool {
+	position := fileInfo.Position(pos)
+	if len(path) == 0 || path[len(path)-1] != file {
+		t.Errorf("%v: path does not end at the file", position)
+		return false
+	}
+	for i := 0; i < len(path)-1; i++ {
+		if !isChild(path[i], path[i+1]) {
+			t.Errorf("%v: %T is not a child of %T", position, path[i], path[i+1])
+			return false
+		}
+		if !encloses(path[i], pos, pos) {
+			t.Errorf("%v: %T in the path does not enclose the position", position, path[i])
+			return false
+		}
+	}
+	for _, child := range childrenOf(path[0]) {
+		if encloses(child, pos, pos) {
+			t.Errorf("%v: %T is not the innermost node, its child %T also encloses the position", position, path[0], child)
+			return false
+		}
+	}
+	return true
+}
+
+func isChild(child, parent ast.Node) bool {
+	for _, n := range childrenOf(parent) {
+		if n == child {
+			return true
+		}
+	}
+	return false
+}
+
+func TestPathEnclosingIntervalComments(t *testing.T) {
+	src := "// Doc\nInt x = 1; // Trailing\n\nInt\nf() {\n\t/* Inside */\n\treturn  x;\n}\n"
+	fileInfo := &token.FileInfo{
+		Filename: "test.spl",
+	}
+	p := &parser.Parser{}
+	p.Init(fileInfo, []byte(src))
+	file := p.Parse()
+
+	tests := []struct {
+		offset int
+		path   []string
+	}{
+		{2, []string{"*ast.Comment", "*ast.CommentGroup", "*ast.File"}},
+		{0, []string{"*ast.Comment", "*ast.CommentGroup", "*ast.File"}},
+		{7, []string{"*ast.Identifier", "*ast.BasicType", "*ast.VariableDeclaration", "*ast.File"}},
+		{10, []string{"*ast.VariableDeclaration", "*ast.File"}},
+		{20, []string{"*ast.Comment", "*ast.CommentGroup", "*ast.File"}},
+		{46, []string{"*ast.Comment", "*ast.CommentGroup", "*ast.File"}},
+		{62, []string{"*ast.ReturnStatement", "*ast.FunctionDeclaration", "*ast.File"}},
+		{64, []string{"*ast.Identifier", "*ast.ReturnStatement", "*ast.FunctionDeclaration", "*ast.File"}},
+		{len(src) - 1, []string{"*ast.File"}},
+	}
+	for _, test := range tests {
+		path, _ := PathEnclosingInterval(file, fileInfo.Pos(test.offset), fileInfo.Pos(test.offset))
+		var types []string
+		for _, n := range path {
+			types = append(types, fmt.Sprintf("%T", n))
+		}
+		if strings.Join(types, " ") != strings.Join(test.path, " ") {
+			t.Errorf("Path at offset %d (%q): got %v, expected %v", test.offset, src[test.offset], types, test.path)
+		}
+	}
+}
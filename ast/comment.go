@@ -1,6 +1,8 @@
 package ast
 
 import (
+	"strings"
+
 	"github.com/Minnozz/gospl/token"
 )
 
@@ -11,3 +13,57 @@ type Comment struct {
 
 func (c *Comment) Pos() token.Pos { return c.TextPos }
 func (c *Comment) End() token.Pos { return token.Pos(int(c.TextPos) + len(c.Text)) }
+
+// CommentGroup is a sequence of comments without other tokens or empty lines between them.  The Doc field of a top-level
+// declaration is the comment group directly above it, or nil.
+type CommentGroup struct {
+	List []*Comment // len(List) > 0
+}
+
+func (g *CommentGroup) Pos() token.Pos { return g.List[0].Pos() }
+func (g *CommentGroup) End() token.Pos { return g.List[len(g.List)-1].End() }
+
+// Text returns the text of the comments without comment markers.  The "*" at the start of the lines of a /** ... */ comment is
+// removed as well, as are extra "*" at the start and end of a block comment.  Leading and trailing empty lines are removed,
+// as are multiple empty lines in a row and trailing whitespace.  The result ends with a newline, unless it is empty.
+func (g *CommentGroup) Text() string {
+	if g == nil {
+		return ""
+	}
+
+	var lines []string
+	for _, c := range g.List {
+		text := c.Text
+		if strings.HasPrefix(text, "//") {
+			lines = append(lines, strings.TrimPrefix(text[2:], " "))
+			continue
+		}
+
+		// Block comment; the markers are removed before the extra "*" of /** and **/, because they overlap in /**/
+		text = strings.Trim(strings.TrimSuffix(strings.TrimPrefix(text, "/*"), "*/"), "*")
+		for i, line := range strings.Split(text, "\n") {
+			if trimmed := strings.TrimLeft(line, " \t"); i > 0 && strings.HasPrefix(trimmed, "*") {
+				line = trimmed[1:]
+			}
+			lines = append(lines, strings.TrimPrefix(line, " "))
+		}
+	}
+
+	// Remove trailing whitespace and multiple empty lines
+	var out []string
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" && (len(out) == 0 || out[len(out)-1] == "") {
+			continue
+		}
+		out = append(out, line)
+	}
+	for len(out) > 0 && out[len(out)-1] == "" {
+		out = out[:len(out)-1]
+	}
+
+	if len(out) == 0 {
+		return ""
+	}
+	return strings.Join(out, "\n") + "\n"
+}
@@ -0,0 +1,68 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/Minnozz/gospl/ast"
+	"github.com/Minnozz/gospl/token"
+)
+
+func TestCommentGroupText(t *testing.T) {
+	tests := []struct {
+		comments []string
+		text     string
+	}{
+		{[]string{"// Line comment"}, "Line comment\n"},
+		{[]string{"//No space", "//  Indented", "//", "//", "// After empty lines  "}, "No space\n Indented\n\nAfter empty lines\n"},
+		{[]string{"/* Block comment */"}, "Block comment\n"},
+		{[]string{"/**\n * Prints the return value if it is non-zero\n */"}, "Prints the return value if it is non-zero\n"},
+		{[]string{"/*\n\tFirst\n\n\n\tSecond\n*/"}, "\tFirst\n\n\tSecond\n"},
+		{[]string{"/* Block */", "// and line"}, "Block\nand line\n"},
+		{[]string{"//", "/* */"}, ""},
+		{[]string{"/**/"}, ""},
+		{[]string{"/***/"}, ""},
+		{[]string{"/** x **/"}, "x\n"},
+		{[]string{"/*** x * y ***/"}, "x * y\n"},
+		{[]string{"/*\n * x\n **/"}, "x\n"},
+	}
+
+	for _, test := range tests {
+		g := &ast.CommentGroup{}
+		for _, text := range test.comments {
+			g.List = append(g.List, &ast.Comment{
+				TextPos: token.Pos(1),
+				Text:    text,
+			})
+		}
+		if got := g.Text(); got != test.text {
+			t.Errorf("Text of %q is %q, expected %q", test.comments, got, test.text)
+		}
+	}
+}
+
+func TestDocComments(t *testing.T) {
+	file, _ := parseTestSource(t, commentTestSource+`
+/* Not directly above the declaration */
+
+Int last = 0;
+`)
+
+	expected := []string{
+		"Global counter\n",
+		"Prints the return value if it is non-zero\n",
+		"",
+		"",
+	}
+	for i, decl := range file.Declarations {
+		var doc *ast.CommentGroup
+		switch d := decl.(type) {
+		case *ast.VariableDeclaration:
+			doc = d.Doc
+		case *ast.FunctionDeclaration:
+			doc = d.Doc
+		}
+		if got := doc.Text(); got != expected[i] {
+			t.Errorf("Doc of declaration %d is %q, expected %q", i, got, expected[i])
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package ast
+
+import (
+	"sort"
+
+	"github.com/Minnozz/gospl/token"
+)
+
+// CommentMap maps a node to the comment groups that are associated with it.  See NewCommentMap for how comments are
+// associated.
+type CommentMap map[Node][]*CommentGroup
+
+func (cmap CommentMap) addComment(n Node, c *CommentGroup) {
+	cmap[n] = append(cmap[n], c)
+}
+
+// NewCommentMap associates every comment group in comments with a node in the tree rooted at node.  A comment group is
+// associated with:
+//   - the preceding node, if it starts on the line where that node ends, or if it starts on the next line and is followed
+//     by an empty line;
+//   - otherwise, the following node, or the last node if there is none.
+//
+// Declarations, statements, record fields, parameters and match cases take precedence over the other nodes, so a comment
+// after a statement is associated with the statement rather than with its last expression.
+func NewCommentMap(fileInfo *token.FileInfo, node Node, comments []*CommentGroup) CommentMap {
+	if len(comments) == 0 {
+		return nil
+	}
+
+	cmap := make(CommentMap)
+
+	groups := make([]*CommentGroup, len(comments))
+	copy(groups, comments)
+	sortComments(groups)
+
+	nodes := nodeList(node)
+	nodes = append(nodes, nil) // Sentinel
+
+	var (
+		p     Node           // Previous node
+		pEnd  token.Position // End of p
+		pg    Node           // Previous node group: the last important node that ends before the current comment
+		pgEnd token.Position // End of pg
+		stack nodeStack      // Important nodes around the current position
+	)
+
+	i := 0
+	for _, q := range nodes {
+		var qPos token.Position
+		if q != nil {
+			qPos = fileInfo.Position(q.Pos())
+		} else {
+			// Process all remaining comments before the sentinel
+			const infinity = 1 << 30
+			qPos.Offset = infinity
+			qPos.Line = infinity
+		}
+
+		// Process the comments before q
+		for ; i < len(groups); i++ {
+			g := groups[i]
+			gPos, gEnd := fileInfo.Position(g.Pos()), fileInfo.Position(g.End())
+			if gEnd.Offset > qPos.Offset {
+				break
+			}
+
+			if top := stack.pop(g.Pos()); top != nil {
+				pg = top
+				pgEnd = fileInfo.Position(pg.End())
+			}
+
+			var assoc Node
+			switch {
+			case pg != nil && (pgEnd.Line == gPos.Line || pgEnd.Line+1 == gPos.Line && gEnd.Line+1 < qPos.Line || q == nil):
+				assoc = pg
+			case p != nil && (pEnd.Line == gPos.Line || pEnd.Line+1 == gPos.Line && gEnd.Line+1 < qPos.Line || q == nil):
+				assoc = p
+			case q != nil:
+				assoc = q
+			default:
+				// No nodes other than the root
+				assoc = node
+			}
+			cmap.addComment(assoc, g)
+		}
+
+		if q == nil {
+			break
+		}
+
+		p = q
+		pEnd = fileInfo.Position(p.End())
+
+		if isNodeGroup(q) {
+			stack.push(q)
+		}
+	}
+
+	return cmap
+}
+
+// isNodeGroup reports whether comments near n should be associated with n rather than with the nodes inside it
+func isNodeGroup(n Node) bool {
+	switch n.(type) {
+	case *ImportDeclaration, *FixityDeclaration, *VariableDeclaration, *FunctionDeclaration, *FunctionParameter,
+		*RecordDeclaration, *RecordField, *TypeDeclaration, *DataDeclaration, *DataConstructor,
+		*BlockStatement, *ReturnStatement, *IfStatement, *WhileStatement, *ForStatement, *BreakStatement,
+		*ContinueStatement, *MatchStatement, *MatchCase, *AssignmentStatement, *IncrementStatement,
+		*FunctionCallStatement:
+		return true
+	}
+	return false
+}
+
+// nodeList returns the nodes of the tree rooted at n in source order, without comments
+func nodeList(n Node) []Node {
+	var list []Node
+	Inspect(n, func(n Node) bool {
+		switch n.(type) {
+		case *CommentGroup, *Comment:
+			return false
+		case *File:
+			// A file starts at its first declaration, which should get the comments before it instead
+			return true
+		}
+		list = append(list, n)
+		return true
+	})
+
+	// Parents come before their children with the same position
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Pos() < list[j].Pos()
+	})
+
+	return list
+}
+
+func sortComments(list []*CommentGroup) {
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Pos() < list[j].Pos()
+	})
+}
+
+type nodeStack []Node
+
+// push pops the nodes that end before n, and pushes n
+func (s *nodeStack) push(n Node) {
+	s.pop(n.Pos())
+	*s = append(*s, n)
+}
+
+// pop pops the nodes that end at or before pos, and returns the last one popped
+func (s *nodeStack) pop(pos token.Pos) (top Node) {
+	i := len(*s)
+	for i > 0 && (*s)[i-1].End() <= pos {
+		top = (*s)[i-1]
+		i--
+	}
+	*s = (*s)[:i]
+	return top
+}
+
+// Update replaces old by new in the map, keeping the comments of old, and returns new.  It is meant to be used when old is
+// replaced by new in the tree.
+func (cmap CommentMap) Update(old, new Node) Node {
+	if list := cmap[old]; len(list) > 0 {
+		delete(cmap, old)
+		cmap[new] = append(cmap[new], list...)
+	}
+	return new
+}
+
+// Filter returns a new comment map with only the comments of the nodes in the tree rooted at node
+func (cmap CommentMap) Filter(node Node) CommentMap {
+	umap := make(CommentMap)
+	Inspect(node, func(n Node) bool {
+		if g := cmap[n]; len(g) > 0 {
+			umap[n] = g
+		}
+		return true
+	})
+	return umap
+}
+
+// Comments returns the comment groups in the map, in source order
+func (cmap CommentMap) Comments() []*CommentGroup {
+	list := make([]*CommentGroup, 0, len(cmap))
+	for _, e := range cmap {
+		list = append(list, e...)
+	}
+	sortComments(list)
+	return list
+}
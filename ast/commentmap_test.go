@@ -0,0 +1,138 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/Minnozz/gospl/ast"
+)
+
+const commentTestSource = `// Global counter
+Int counter = 0; // Starts at zero
+
+/**
+ * Prints the return value if it is non-zero
+ */
+Void
+check(Int ret) {
+	/* This is a multi-line comment on a single line */
+	if(ret != 0) {
+		print(ret);
+		// Count the error
+		counter = counter + 1;
+	}
+}
+
+Int
+main() {
+	Int return_value = 0;
+
+	// Check return value before returning
+	// (this comment is in the same group)
+	check(return_value);
+
+	return return_value; // Done
+}
+
+// End of file
+`
+
+func commentTexts(groups []*ast.CommentGroup) []string {
+	var texts []string
+	for _, g := range groups {
+		text := ""
+		for i, c := range g.List {
+			if i > 0 {
+				text += "|"
+			}
+			text += c.Text
+		}
+		texts = append(texts, text)
+	}
+	return texts
+}
+
+func TestCommentGroups(t *testing.T) {
+	file, _ := parseTestSource(t, commentTestSource)
+
+	expected := []string{
+		"// Global counter",
+		"// Starts at zero",
+		"/**\n * Prints the return value if it is non-zero\n */",
+		"/* This is a multi-line comment on a single line */",
+		"// Count the error",
+		"// Check return value before returning|// (this comment is in the same group)",
+		"// Done",
+		"// End of file",
+	}
+	got := commentTexts(file.Comments)
+	if len(got) != len(expected) {
+		t.Fatalf("Got comment groups %q, expected %q", got, expected)
+	}
+	for i := range got {
+		if got[i] != expected[i] {
+			t.Errorf("Comment group %d is %q, expected %q", i, got[i], expected[i])
+		}
+	}
+}
+
+func TestCommentMap(t *testing.T) {
+	file, fileInfo := parseTestSource(t, commentTestSource)
+	cmap := ast.NewCommentMap(fileInfo, file, file.Comments)
+
+	counter := file.Declarations[0].(*ast.VariableDeclaration)
+	check := file.Declarations[1].(*ast.FunctionDeclaration)
+	ifStmt := check.Statements[0].(*ast.IfStatement)
+	increment := ifStmt.Body.(*ast.BlockStatement).List[1]
+	main := file.Declarations[2].(*ast.FunctionDeclaration)
+	call := main.Statements[0]
+	ret := main.Statements[1]
+
+	expected := []struct {
+		node     ast.Node
+		comments []string
+	}{
+		{counter, []string{"// Global counter", "// Starts at zero"}},
+		{check, []string{"/**\n * Prints the return value if it is non-zero\n */"}},
+		{ifStmt, []string{"/* This is a multi-line comment on a single line */"}},
+		{increment, []string{"// Count the error"}},
+		{call, []string{"// Check return value before returning|// (this comment is in the same group)"}},
+		{ret, []string{"// Done"}},
+		{main, []string{"// End of file"}},
+	}
+
+	if len(cmap) != len(expected) {
+		t.Errorf("Expected comments for %d nodes, got %d", len(expected), len(cmap))
+	}
+	for _, e := range expected {
+		got := commentTexts(cmap[e.node])
+		if len(got) != len(e.comments) {
+			t.Errorf("Comments of %T are %q, expected %q", e.node, got, e.comments)
+			continue
+		}
+		for i := range got {
+			if got[i] != e.comments[i] {
+				t.Errorf("Comments of %T are %q, expected %q", e.node, got, e.comments)
+				break
+			}
+		}
+	}
+
+	if got := cmap.Comments(); len(got) != len(file.Comments) || got[0] != file.Comments[0] || got[len(got)-1] != file.Comments[len(got)-1] {
+		t.Errorf("Comments() does not return all comments in source order")
+	}
+
+	// Filter keeps only the comments inside a subtree
+	filtered := cmap.Filter(check)
+	if len(filtered) != 3 || len(filtered[check]) != 1 || len(filtered[ifStmt]) != 1 || len(filtered[increment]) != 1 {
+		t.Errorf("Unexpected filtered comment map: %v", filtered)
+	}
+
+	// Update moves comments to a replacement node
+	replacement := &ast.ReturnStatement{}
+	if cmap.Update(ret, replacement) != replacement {
+		t.Errorf("Update did not return the new node")
+	}
+	if len(cmap[ret]) != 0 || len(cmap[replacement]) != 1 {
+		t.Errorf("Update did not move the comments of the old node")
+	}
+}
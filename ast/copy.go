@@ -0,0 +1,75 @@
+package ast
+
+import (
+	"reflect"
+)
+
+// Copy returns a deep copy of the tree rooted at node.  Nodes that occur more than once in the tree (like the imports of a
+// file, which are also in its declarations) are copied once, so the copy shares them in the same way.
+//
+// Objects and scopes are not copied, so the copy is unresolved.
+func Copy(node Node) Node {
+	if node == nil {
+		return nil
+	}
+	c := make(copier)
+	return c.copy(reflect.ValueOf(node)).Interface().(Node)
+}
+
+type copierKey struct {
+	t reflect.Type
+	p uintptr
+}
+
+// copier holds the copies of the nodes copied so far
+type copier map[copierKey]reflect.Value
+
+func (c copier) copy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Interface:
+		nv := reflect.New(v.Type()).Elem()
+		if !v.IsNil() {
+			nv.Set(c.copy(v.Elem()))
+		}
+		return nv
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		key := copierKey{v.Type(), v.Pointer()}
+		if nv, ok := c[key]; ok {
+			return nv
+		}
+		nv := reflect.New(v.Type().Elem())
+		c[key] = nv
+		for i := 0; i < v.Elem().NumField(); i++ {
+			if !isResolutionField(v.Elem().Type().Field(i)) {
+				nv.Elem().Field(i).Set(c.copy(v.Elem().Field(i)))
+			}
+		}
+		return nv
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		nv := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			nv.Index(i).Set(c.copy(v.Index(i)))
+		}
+		return nv
+	}
+
+	// Positions, tokens, names and other plain values
+	return v
+}
+
+var (
+	objectType = reflect.TypeOf((*Object)(nil))
+	scopeType  = reflect.TypeOf((*Scope)(nil))
+)
+
+// isResolutionField reports whether f holds the result of name resolution (Identifier.Obj, scopes and File.Unresolved).
+// These fields are left out by Copy, Equal and MarshalJSON.
+func isResolutionField(f reflect.StructField) bool {
+	return f.Type == objectType || f.Type == scopeType || f.Name == "Unresolved"
+}
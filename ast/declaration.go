@@ -15,26 +15,66 @@ type BadDeclaration struct {
 func (d *BadDeclaration) Pos() token.Pos { return d.From }
 func (d *BadDeclaration) End() token.Pos { return d.To }
 
+type ImportDeclaration struct {
+	Doc       *CommentGroup
+	Import    token.Pos
+	Path      *LiteralExpression
+	Semicolon token.Pos
+}
+
+func (d *ImportDeclaration) Pos() token.Pos { return d.Import }
+func (d *ImportDeclaration) End() token.Pos { return d.Semicolon + 1 }
+
+type FixityDeclaration struct {
+	Doc           *CommentGroup
+	Fixity        token.Pos
+	Associativity token.Token // INFIX, INFIXL or INFIXR
+	Precedence    *LiteralExpression
+	Operator      *Identifier
+	Function      *Identifier
+	Semicolon     token.Pos
+}
+
+func (d *FixityDeclaration) Pos() token.Pos { return d.Fixity }
+func (d *FixityDeclaration) End() token.Pos { return d.Semicolon + 1 }
+
 type VariableDeclaration struct {
-	Type        Type
-	Name        *Identifier
+	Doc         *CommentGroup
+	Var         token.Pos   // Position of "var" if Type is nil
+	Type        Type        // Nil if the type is inferred
+	Name        *Identifier // Nil if Pattern is set
+	Pattern     Expression  // Tuple or cons pattern of identifiers, for destructuring
 	Initializer Expression
 	Semicolon   token.Pos
 }
 
-func (d *VariableDeclaration) Pos() token.Pos { return d.Type.Pos() }
+func (d *VariableDeclaration) Pos() token.Pos {
+	if d.Type == nil {
+		return d.Var
+	}
+	return d.Type.Pos()
+}
+
 func (d *VariableDeclaration) End() token.Pos { return d.Semicolon + 1 }
 
 type FunctionDeclaration struct {
+	Doc               *CommentGroup
+	Context           *TypeContext // Nil if there are no constraints
 	ReturnType        Type
 	Name              *Identifier
 	Parameters        *FunctionParameters
 	Variables         []*VariableDeclaration
 	Statements        []Statement
 	CurlyBracketClose token.Pos
+	Scope             *Scope // Type variables, parameters and local variables
 }
 
-func (d *FunctionDeclaration) Pos() token.Pos { return d.ReturnType.Pos() }
+func (d *FunctionDeclaration) Pos() token.Pos {
+	if d.Context != nil {
+		return d.Context.Pos()
+	}
+	return d.ReturnType.Pos()
+}
 func (d *FunctionDeclaration) End() token.Pos { return d.CurlyBracketClose + 1 }
 
 type FunctionParameters struct {
@@ -53,3 +93,61 @@ type FunctionParameter struct {
 
 func (d *FunctionParameter) Pos() token.Pos { return d.Type.Pos() }
 func (d *FunctionParameter) End() token.Pos { return d.Name.End() }
+
+type RecordDeclaration struct {
+	Doc               *CommentGroup
+	Record            token.Pos
+	Name              *Identifier
+	CurlyBracketOpen  token.Pos
+	Fields            []*RecordField
+	CurlyBracketClose token.Pos
+}
+
+func (d *RecordDeclaration) Pos() token.Pos { return d.Record }
+func (d *RecordDeclaration) End() token.Pos { return d.CurlyBracketClose + 1 }
+
+type RecordField struct {
+	Type      Type
+	Name      *Identifier
+	Semicolon token.Pos
+}
+
+func (d *RecordField) Pos() token.Pos { return d.Type.Pos() }
+func (d *RecordField) End() token.Pos { return d.Semicolon + 1 }
+
+type TypeDeclaration struct {
+	Doc            *CommentGroup
+	TypeKeyword    token.Pos
+	Name           *Identifier
+	TypeParameters []*Identifier
+	Type           Type
+	Semicolon      token.Pos
+}
+
+func (d *TypeDeclaration) Pos() token.Pos { return d.TypeKeyword }
+func (d *TypeDeclaration) End() token.Pos { return d.Semicolon + 1 }
+
+type DataDeclaration struct {
+	Doc            *CommentGroup
+	Data           token.Pos
+	Name           *Identifier
+	TypeParameters []*Identifier
+	Constructors   []*DataConstructor
+	Semicolon      token.Pos
+}
+
+func (d *DataDeclaration) Pos() token.Pos { return d.Data }
+func (d *DataDeclaration) End() token.Pos { return d.Semicolon + 1 }
+
+type DataConstructor struct {
+	Name   *Identifier
+	Fields []Type
+}
+
+func (d *DataConstructor) Pos() token.Pos { return d.Name.Pos() }
+func (d *DataConstructor) End() token.Pos {
+	if len(d.Fields) > 0 {
+		return d.Fields[len(d.Fields)-1].End()
+	}
+	return d.Name.End()
+}
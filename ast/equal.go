@@ -0,0 +1,112 @@
+package ast
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// EqualOptions control which parts of the trees are compared by Equal and Diff
+type EqualOptions struct {
+	IgnorePositions bool // Ignore token.Pos fields
+	IgnoreComments  bool // Ignore comments, including doc comments of declarations
+}
+
+var commentGroupType = reflect.TypeOf((*CommentGroup)(nil))
+
+// Equal reports whether the trees rooted at a and b are structurally equal.  The results of name resolution are not
+// compared.
+func Equal(a, b Node, opts EqualOptions) bool {
+	return Diff(a, b, opts) == ""
+}
+
+// Diff describes the first difference between the trees rooted at a and b, or returns an empty string if they are equal.
+// The difference is described by the path of fields from a to the differing value, e.g.
+// `File.Declarations[0].Name.Name: "f" != "g"`.
+func Diff(a, b Node, opts EqualOptions) string {
+	av, bv := reflect.ValueOf(&a).Elem(), reflect.ValueOf(&b).Elem()
+	path := "Node"
+	if a != nil && b != nil && reflect.TypeOf(a) == reflect.TypeOf(b) {
+		path = reflect.TypeOf(a).Elem().Name()
+	}
+	return (&comparer{opts}).diff(path, av, bv)
+}
+
+type comparer struct {
+	opts EqualOptions
+}
+
+func (c *comparer) diff(path string, a, b reflect.Value) string {
+	switch a.Kind() {
+	case reflect.Interface:
+		if a.IsNil() || b.IsNil() || a.Elem().Type() != b.Elem().Type() {
+			if a.IsNil() && b.IsNil() {
+				return ""
+			}
+			return fmt.Sprintf("%s: %s != %s", path, describeType(a), describeType(b))
+		}
+		return c.diff(path, a.Elem(), b.Elem())
+	case reflect.Ptr:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() && b.IsNil() {
+				return ""
+			}
+			return fmt.Sprintf("%s: %s != %s", path, describeType(a), describeType(b))
+		}
+		if a.Pointer() == b.Pointer() {
+			return ""
+		}
+		t := a.Type().Elem()
+		for i := 0; i < t.NumField(); i++ {
+			if isResolutionField(t.Field(i)) || c.ignore(t.Field(i).Type) {
+				continue
+			}
+			if d := c.diff(path+"."+t.Field(i).Name, a.Elem().Field(i), b.Elem().Field(i)); d != "" {
+				return d
+			}
+		}
+		return ""
+	case reflect.Slice:
+		if a.Len() != b.Len() {
+			return fmt.Sprintf("%s: length %d != %d", path, a.Len(), b.Len())
+		}
+		for i := 0; i < a.Len(); i++ {
+			if d := c.diff(fmt.Sprintf("%s[%d]", path, i), a.Index(i), b.Index(i)); d != "" {
+				return d
+			}
+		}
+		return ""
+	}
+
+	// Positions, tokens, names and other plain values
+	if a.Interface() != b.Interface() {
+		if a.Kind() == reflect.String {
+			return fmt.Sprintf("%s: %q != %q", path, a.Interface(), b.Interface())
+		}
+		return fmt.Sprintf("%s: %v != %v", path, a.Interface(), b.Interface())
+	}
+	return ""
+}
+
+func (c *comparer) ignore(t reflect.Type) bool {
+	switch {
+	case c.opts.IgnorePositions && t == posType:
+		return true
+	case c.opts.IgnoreComments && (t == commentGroupType || t.Kind() == reflect.Slice && t.Elem() == commentGroupType):
+		return true
+	}
+	return false
+}
+
+// describeType describes the type of a node for Diff, e.g. "*ast.Identifier" or "nil"
+func describeType(v reflect.Value) string {
+	if v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "nil"
+		}
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		return "nil"
+	}
+	return v.Type().String()
+}
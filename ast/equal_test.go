@@ -0,0 +1,87 @@
+package ast_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/Minnozz/gospl/ast"
+)
+
+func TestCopy(t *testing.T) {
+	files, err := filepath.Glob("../testdata/valid/*.spl")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("Error reading test directory: %v", err)
+	}
+
+	for _, filename := range files {
+		src, err := ioutil.ReadFile(filename)
+		if err != nil {
+			t.Fatalf("Error reading test %s: %v", filename, err)
+		}
+		file, _ := parseTestSource(t, string(src))
+		copied := ast.Copy(file).(*ast.File)
+
+		if d := ast.Diff(file, copied, ast.EqualOptions{}); d != "" {
+			t.Errorf("%s: copy is different: %s", filename, d)
+		}
+
+		// The copy must not share any nodes with the original
+		original := make(map[ast.Node]bool)
+		ast.Inspect(file, func(n ast.Node) bool {
+			original[n] = true
+			return true
+		})
+		ast.Inspect(copied, func(n ast.Node) bool {
+			if original[n] {
+				t.Errorf("%s: copy shares %T at %d with the original", filename, n, n.Pos())
+			}
+			return true
+		})
+
+		// Doc comments must still be the groups in the list of comments
+		for _, decl := range copied.Declarations {
+			if fn, ok := decl.(*ast.FunctionDeclaration); ok && fn.Doc != nil && !containsGroup(copied.Comments, fn.Doc) {
+				t.Errorf("%s: doc comment of %s is not in the comments of the copied file", filename, fn.Name.Name)
+			}
+		}
+	}
+}
+
+func containsGroup(list []*ast.CommentGroup, g *ast.CommentGroup) bool {
+	for _, c := range list {
+		if c == g {
+			return true
+		}
+	}
+	return false
+}
+
+func TestEqual(t *testing.T) {
+	tests := []struct {
+		a, b string
+		opts ast.EqualOptions
+		diff string
+	}{
+		{"Int x = 1 + 2;", "Int x = 1 + 2;", ast.EqualOptions{}, ""},
+		{"Int x = 1 + 2;", "Int  x=1+2 ;", ast.EqualOptions{IgnorePositions: true}, ""},
+		{"Int x = 1 + 2;", "Int  x=1+2 ;", ast.EqualOptions{}, "File.Declarations[0].Name.NamePos: 5 != 6"},
+		{"Int x = 1 + 2;", "Int x = 1 - 2;", ast.EqualOptions{}, "File.Declarations[0].Initializer.Operator: PLUS != MINUS"},
+		{"Int x = 1 + 2;", "Int x = 1 + y;", ast.EqualOptions{}, "File.Declarations[0].Initializer.Right: *ast.LiteralExpression != *ast.Identifier"},
+		{"Int x = f(1);", "Int x = f(1, 2);", ast.EqualOptions{IgnorePositions: true}, "File.Declarations[0].Initializer.Arguments: length 1 != 2"},
+		{"Int x = 1;", "Bool x = 1;", ast.EqualOptions{}, `File.Declarations[0].Type.Name.Name: "Int" != "Bool"`},
+		{"// X\nInt x = 1;", "Int x = 1;", ast.EqualOptions{IgnorePositions: true}, "File.Declarations[0].Doc: *ast.CommentGroup != nil"},
+		{"// X\nInt x = 1;", "Int x = 1; // Y", ast.EqualOptions{IgnorePositions: true, IgnoreComments: true}, ""},
+	}
+
+	for _, test := range tests {
+		a, _ := parseTestSource(t, test.a)
+		b, _ := parseTestSource(t, test.b)
+		if d := ast.Diff(a, b, test.opts); d != test.diff {
+			t.Errorf("Diff of %q and %q: got %q, expected %q", test.a, test.b, d, test.diff)
+		}
+		if equal := ast.Equal(a, b, test.opts); equal != (test.diff == "") {
+			t.Errorf("Equal of %q and %q: got %v", test.a, test.b, equal)
+		}
+	}
+}
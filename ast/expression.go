@@ -18,6 +18,7 @@ func (e *BadExpression) End() token.Pos { return e.To }
 type Identifier struct {
 	NamePos token.Pos
 	Name    string
+	Obj     *Object // Object the identifier refers to, or nil
 }
 
 func (e *Identifier) Pos() token.Pos { return e.NamePos }
@@ -44,21 +45,39 @@ func (e *UnaryExpression) End() token.Pos { return e.Operand.End() }
 type BinaryExpression struct {
 	Left     Expression
 	Operator token.Token
+	Symbol   string // User-defined operator, if Operator is token.OPERATOR
 	Right    Expression
 }
 
 func (e *BinaryExpression) Pos() token.Pos { return e.Left.Pos() }
 func (e *BinaryExpression) End() token.Pos { return e.Right.End() }
 
+func (e *BinaryExpression) OperatorString() string {
+	if e.Operator == token.OPERATOR {
+		return e.Symbol
+	}
+	return e.Operator.Print()
+}
+
 type FunctionCallExpression struct {
-	Name              *Identifier
+	Function          Expression
 	Arguments         []Expression
 	RoundBracketClose token.Pos
 }
 
-func (e *FunctionCallExpression) Pos() token.Pos { return e.Name.Pos() }
+func (e *FunctionCallExpression) Pos() token.Pos { return e.Function.Pos() }
 func (e *FunctionCallExpression) End() token.Pos { return e.RoundBracketClose + 1 }
 
+type LambdaExpression struct {
+	Backslash  token.Pos
+	Parameters []*Identifier
+	Arrow      token.Pos
+	Body       Expression
+}
+
+func (e *LambdaExpression) Pos() token.Pos { return e.Backslash }
+func (e *LambdaExpression) End() token.Pos { return e.Body.End() }
+
 type ParenthesizedExpression struct {
 	RoundBracketOpen  token.Pos
 	Expression        Expression
@@ -70,10 +89,65 @@ func (e *ParenthesizedExpression) End() token.Pos { return e.RoundBracketClose +
 
 type TupleExpression struct {
 	RoundBracketOpen  token.Pos
-	Left              Expression
-	Right             Expression
+	Elements          []Expression // At least two
 	RoundBracketClose token.Pos
 }
 
 func (e *TupleExpression) Pos() token.Pos { return e.RoundBracketOpen }
 func (e *TupleExpression) End() token.Pos { return e.RoundBracketClose + 1 }
+
+type ListExpression struct {
+	SquareBracketOpen  token.Pos
+	Elements           []Expression
+	SquareBracketClose token.Pos
+}
+
+func (e *ListExpression) Pos() token.Pos { return e.SquareBracketOpen }
+func (e *ListExpression) End() token.Pos { return e.SquareBracketClose + 1 }
+
+type RangeExpression struct {
+	SquareBracketOpen  token.Pos
+	Low                Expression
+	High               Expression
+	SquareBracketClose token.Pos
+}
+
+func (e *RangeExpression) Pos() token.Pos { return e.SquareBracketOpen }
+func (e *RangeExpression) End() token.Pos { return e.SquareBracketClose + 1 }
+
+type ComprehensionExpression struct {
+	SquareBracketOpen  token.Pos
+	Value              Expression
+	Qualifiers         []Node // *ComprehensionGenerator, or Expression for a guard
+	SquareBracketClose token.Pos
+}
+
+func (e *ComprehensionExpression) Pos() token.Pos { return e.SquareBracketOpen }
+func (e *ComprehensionExpression) End() token.Pos { return e.SquareBracketClose + 1 }
+
+type ComprehensionGenerator struct {
+	Pattern   Expression
+	LeftArrow token.Pos
+	List      Expression
+}
+
+func (e *ComprehensionGenerator) Pos() token.Pos { return e.Pattern.Pos() }
+func (e *ComprehensionGenerator) End() token.Pos { return e.List.End() }
+
+type RecordExpression struct {
+	Name              *Identifier
+	CurlyBracketOpen  token.Pos
+	Values            []Expression
+	CurlyBracketClose token.Pos
+}
+
+func (e *RecordExpression) Pos() token.Pos { return e.Name.Pos() }
+func (e *RecordExpression) End() token.Pos { return e.CurlyBracketClose + 1 }
+
+type FieldExpression struct {
+	Expression Expression
+	Field      *Identifier // Name of the field, or index of the tuple element (0, 1, ...)
+}
+
+func (e *FieldExpression) Pos() token.Pos { return e.Expression.Pos() }
+func (e *FieldExpression) End() token.Pos { return e.Field.End() }
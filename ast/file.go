@@ -6,7 +6,10 @@ import (
 
 type File struct {
 	Declarations []Declaration
-	Comments     []*Comment
+	Imports      []*ImportDeclaration // Also in Declarations
+	Comments     []*CommentGroup
+	Scope        *Scope        // Top-level declarations
+	Unresolved   []*Identifier // Identifiers that do not refer to a declaration in this file, a builtin or, once loaded, an import
 }
 
 func (f *File) Pos() token.Pos {
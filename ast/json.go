@@ -0,0 +1,356 @@
+package ast
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/Minnozz/gospl/token"
+)
+
+// Concrete node types by kind, as used in the "kind" field of JSON objects
+var nodeKinds = make(map[string]reflect.Type)
+
+func init() {
+	for _, n := range []Node{
+		// File and comments
+		(*File)(nil), (*Comment)(nil), (*CommentGroup)(nil),
+
+		// Declarations
+		(*BadDeclaration)(nil), (*ImportDeclaration)(nil), (*FixityDeclaration)(nil), (*VariableDeclaration)(nil),
+		(*FunctionDeclaration)(nil), (*FunctionParameters)(nil), (*FunctionParameter)(nil), (*RecordDeclaration)(nil),
+		(*RecordField)(nil), (*TypeDeclaration)(nil), (*DataDeclaration)(nil), (*DataConstructor)(nil),
+
+		// Expressions
+		(*BadExpression)(nil), (*Identifier)(nil), (*LiteralExpression)(nil), (*UnaryExpression)(nil),
+		(*BinaryExpression)(nil), (*FunctionCallExpression)(nil), (*LambdaExpression)(nil), (*ParenthesizedExpression)(nil),
+		(*TupleExpression)(nil), (*ListExpression)(nil), (*RangeExpression)(nil), (*ComprehensionExpression)(nil),
+		(*ComprehensionGenerator)(nil), (*RecordExpression)(nil), (*FieldExpression)(nil),
+
+		// Statements
+		(*BadStatement)(nil), (*BlockStatement)(nil), (*ReturnStatement)(nil), (*IfStatement)(nil), (*WhileStatement)(nil),
+		(*ForStatement)(nil), (*MatchStatement)(nil), (*MatchCase)(nil), (*BreakStatement)(nil), (*ContinueStatement)(nil),
+		(*AssignmentStatement)(nil), (*IncrementStatement)(nil), (*FunctionCallStatement)(nil),
+
+		// Types
+		(*BadType)(nil), (*NamedType)(nil), (*BasicType)(nil), (*TypeVariable)(nil), (*ParenthesizedType)(nil),
+		(*TupleType)(nil), (*FunctionType)(nil), (*ListType)(nil), (*TypeContext)(nil),
+	} {
+		t := reflect.TypeOf(n).Elem()
+		nodeKinds[t.Name()] = t
+	}
+}
+
+var (
+	nodeType  = reflect.TypeOf((*Node)(nil)).Elem()
+	posType   = reflect.TypeOf(token.NoPos)
+	tokenType = reflect.TypeOf(token.INVALID)
+)
+
+// Tokens by name, as used for token.Token fields in JSON objects
+var tokensByName = make(map[string]token.Token)
+
+func init() {
+	for tok := token.Token(0); !strings.HasPrefix(tok.String(), "Token("); tok++ {
+		tokensByName[tok.String()] = tok
+	}
+}
+
+// MarshalJSON encodes the tree rooted at node as JSON.  Every node is an object with its type name in the "kind" field,
+// followed by its fields by name.  Tokens are encoded by name (e.g. "PLUS").
+//
+// If fileInfo is nil, positions are encoded as numbers.  Otherwise they are resolved to "file:line:column" strings.
+//
+// File.Imports is left out, because its declarations are also in File.Declarations.  The results of name resolution
+// (Identifier.Obj, scopes and File.Unresolved) are left out as well.
+func MarshalJSON(node Node, fileInfo *token.FileInfo) ([]byte, error) {
+	e := &jsonEncoder{
+		fileInfo: fileInfo,
+	}
+	if err := e.encode(reflect.ValueOf(&node).Elem()); err != nil {
+		return nil, err
+	}
+	return e.buf.Bytes(), nil
+}
+
+type jsonEncoder struct {
+	fileInfo *token.FileInfo
+	buf      bytes.Buffer
+}
+
+func (e *jsonEncoder) encode(v reflect.Value) error {
+	switch v.Type() {
+	case posType:
+		pos := token.Pos(v.Int())
+		if e.fileInfo == nil || pos == token.NoPos {
+			e.buf.WriteString(strconv.Itoa(int(pos)))
+		} else {
+			e.encodeString(e.fileInfo.Position(pos).String())
+		}
+		return nil
+	case tokenType:
+		e.encodeString(token.Token(v.Int()).String())
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		if v.IsNil() {
+			e.buf.WriteString("null")
+			return nil
+		}
+		if v.Kind() == reflect.Interface {
+			return e.encode(v.Elem())
+		}
+		if !v.Type().Implements(nodeType) {
+			return fmt.Errorf("ast: cannot encode %v as JSON", v.Type())
+		}
+		return e.encodeNode(v)
+	case reflect.Slice:
+		if v.IsNil() {
+			e.buf.WriteString("null")
+			return nil
+		}
+		e.buf.WriteByte('[')
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				e.buf.WriteByte(',')
+			}
+			if err := e.encode(v.Index(i)); err != nil {
+				return err
+			}
+		}
+		e.buf.WriteByte(']')
+		return nil
+	case reflect.String:
+		e.encodeString(v.String())
+		return nil
+	case reflect.Int:
+		e.buf.WriteString(strconv.FormatInt(v.Int(), 10))
+		return nil
+	}
+
+	return fmt.Errorf("ast: cannot encode %v as JSON", v.Type())
+}
+
+func (e *jsonEncoder) encodeNode(v reflect.Value) error {
+	t := v.Elem().Type()
+	if nodeKinds[t.Name()] != t {
+		return fmt.Errorf("ast: cannot encode unknown node type %v as JSON", v.Type())
+	}
+
+	e.buf.WriteString(`{"kind":`)
+	e.encodeString(t.Name())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if t.Name() == "File" && field.Name == "Imports" || isResolutionField(field) {
+			continue
+		}
+
+		e.buf.WriteByte(',')
+		e.encodeString(field.Name)
+		e.buf.WriteByte(':')
+		if err := e.encode(v.Elem().Field(i)); err != nil {
+			return err
+		}
+	}
+	e.buf.WriteByte('}')
+
+	return nil
+}
+
+func (e *jsonEncoder) encodeString(s string) {
+	b, _ := json.Marshal(s)
+	e.buf.Write(b)
+}
+
+// UnmarshalJSON decodes a tree that was encoded by MarshalJSON.  fileInfo is needed to decode positions that were resolved
+// to "file:line:column" strings; it must contain the lines of the file, e.g. because it has been used to parse the file.
+//
+// When a file is decoded, the doc comments of its declarations are the same comment groups as in File.Comments, like in
+// the parsed file.
+func UnmarshalJSON(data []byte, fileInfo *token.FileInfo) (Node, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var raw interface{}
+	if err := dec.Decode(&raw); err != nil {
+		return nil, err
+	}
+
+	d := &jsonDecoder{
+		fileInfo: fileInfo,
+	}
+	v, err := d.decode(raw, nodeType)
+	if err != nil {
+		return nil, err
+	}
+	if v.IsNil() {
+		return nil, nil
+	}
+	return v.Interface().(Node), nil
+}
+
+type jsonDecoder struct {
+	fileInfo *token.FileInfo
+}
+
+func (d *jsonDecoder) decode(raw interface{}, t reflect.Type) (reflect.Value, error) {
+	v := reflect.New(t).Elem()
+
+	switch t {
+	case posType:
+		pos, err := d.decodePos(raw)
+		v.SetInt(int64(pos))
+		return v, err
+	case tokenType:
+		name, ok := raw.(string)
+		tok, found := tokensByName[name]
+		if !ok || !found {
+			return v, fmt.Errorf("ast: invalid token %v", raw)
+		}
+		v.SetInt(int64(tok))
+		return v, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		if raw == nil {
+			return v, nil
+		}
+		n, err := d.decodeNode(raw)
+		if err != nil {
+			return v, err
+		}
+		if !n.Type().AssignableTo(t) {
+			return v, fmt.Errorf("ast: cannot use %v as %v", n.Type(), t)
+		}
+		v.Set(n)
+		return v, nil
+	case reflect.Slice:
+		if raw == nil {
+			return v, nil
+		}
+		list, ok := raw.([]interface{})
+		if !ok {
+			return v, fmt.Errorf("ast: expected array for %v, got %v", t, raw)
+		}
+		v.Set(reflect.MakeSlice(t, len(list), len(list)))
+		for i, el := range list {
+			ev, err := d.decode(el, t.Elem())
+			if err != nil {
+				return v, err
+			}
+			v.Index(i).Set(ev)
+		}
+		return v, nil
+	case reflect.String:
+		s, ok := raw.(string)
+		if !ok {
+			return v, fmt.Errorf("ast: expected string, got %v", raw)
+		}
+		v.SetString(s)
+		return v, nil
+	case reflect.Int:
+		num, ok := raw.(json.Number)
+		if !ok {
+			return v, fmt.Errorf("ast: expected number, got %v", raw)
+		}
+		i, err := num.Int64()
+		v.SetInt(i)
+		return v, err
+	}
+
+	return v, fmt.Errorf("ast: cannot decode %v from JSON", t)
+}
+
+func (d *jsonDecoder) decodeNode(raw interface{}) (reflect.Value, error) {
+	obj, ok := raw.(map[string]interface{})
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("ast: expected node object, got %v", raw)
+	}
+	kind, _ := obj["kind"].(string)
+	t, ok := nodeKinds[kind]
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("ast: unknown node kind %q", obj["kind"])
+	}
+
+	n := reflect.New(t)
+	for name, value := range obj {
+		if name == "kind" {
+			continue
+		}
+		field, ok := t.FieldByName(name)
+		if !ok {
+			return n, fmt.Errorf("ast: unknown field %s in %s", name, kind)
+		}
+		fv, err := d.decode(value, field.Type)
+		if err != nil {
+			return n, err
+		}
+		n.Elem().FieldByIndex(field.Index).Set(fv)
+	}
+
+	if file, ok := n.Interface().(*File); ok {
+		for _, decl := range file.Declarations {
+			if imp, ok := decl.(*ImportDeclaration); ok {
+				file.Imports = append(file.Imports, imp)
+			}
+		}
+		shareDocComments(file)
+	}
+
+	return n, nil
+}
+
+// shareDocComments replaces the doc comments of the declarations in file by the comment groups at the same position in
+// file.Comments, which are decoded separately
+func shareDocComments(file *File) {
+	groups := make(map[token.Pos]*CommentGroup)
+	for _, group := range file.Comments {
+		if group != nil && len(group.List) > 0 {
+			groups[group.Pos()] = group
+		}
+	}
+
+	for _, decl := range file.Declarations {
+		if decl == nil {
+			continue
+		}
+		doc := reflect.ValueOf(decl).Elem().FieldByName("Doc")
+		if !doc.IsValid() || doc.IsNil() {
+			continue
+		}
+		if group := doc.Interface().(*CommentGroup); len(group.List) > 0 && groups[group.Pos()] != nil {
+			doc.Set(reflect.ValueOf(groups[group.Pos()]))
+		}
+	}
+}
+
+func (d *jsonDecoder) decodePos(raw interface{}) (token.Pos, error) {
+	switch raw := raw.(type) {
+	case json.Number:
+		pos, err := raw.Int64()
+		return token.Pos(pos), err
+	case string:
+		if d.fileInfo == nil {
+			return token.NoPos, fmt.Errorf("ast: cannot decode position %q without file info", raw)
+		}
+		// The file name may contain colons
+		parts := strings.Split(raw, ":")
+		if len(parts) >= 3 {
+			line, err1 := strconv.Atoi(parts[len(parts)-2])
+			column, err2 := strconv.Atoi(parts[len(parts)-1])
+			if err1 == nil && err2 == nil {
+				if pos := d.fileInfo.LinePos(line, column); pos != token.NoPos {
+					return pos, nil
+				}
+			}
+		}
+		return token.NoPos, fmt.Errorf("ast: invalid position %q", raw)
+	}
+	return token.NoPos, fmt.Errorf("ast: invalid position %v", raw)
+}
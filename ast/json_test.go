@@ -0,0 +1,117 @@
+package ast_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Minnozz/gospl/ast"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	files, err := filepath.Glob("../testdata/valid/*.spl")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("Error reading test directory: %v", err)
+	}
+
+	for _, filename := range files {
+		src, err := ioutil.ReadFile(filename)
+		if err != nil {
+			t.Fatalf("Error reading test %s: %v", filename, err)
+		}
+		file, fileInfo := parseTestSource(t, string(src))
+
+		// Both with positions as offsets and resolved to lines and columns
+		for _, resolve := range []bool{false, true} {
+			info := fileInfo
+			if !resolve {
+				info = nil
+			}
+
+			data, err := ast.MarshalJSON(file, info)
+			if err != nil {
+				t.Errorf("%s: error marshaling: %v", filename, err)
+				continue
+			}
+			if !json.Valid(data) {
+				t.Errorf("%s: invalid JSON: %s", filename, data)
+				continue
+			}
+
+			node, err := ast.UnmarshalJSON(data, info)
+			if err != nil {
+				t.Errorf("%s: error unmarshaling: %v", filename, err)
+				continue
+			}
+			if d := ast.Diff(file, node, ast.EqualOptions{}); d != "" {
+				t.Errorf("%s: tree changed after marshaling and unmarshaling (resolved positions: %v): %s", filename, resolve, d)
+			}
+		}
+	}
+}
+
+func TestJSONFormat(t *testing.T) {
+	file, fileInfo := parseTestSource(t, "Int x = -y;")
+	expr := file.Declarations[0].(*ast.VariableDeclaration).Initializer
+
+	data, err := ast.MarshalJSON(expr, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"kind":"UnaryExpression","OperatorPos":9,"Operator":"MINUS","Operand":{"kind":"Identifier","NamePos":10,"Name":"y"}}`
+	if string(data) != expected {
+		t.Errorf("Got %s, expected %s", data, expected)
+	}
+
+	data, err = ast.MarshalJSON(expr, fileInfo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"OperatorPos":"test.spl:1:9"`) {
+		t.Errorf("Position is not resolved in %s", data)
+	}
+}
+
+func TestJSONErrors(t *testing.T) {
+	for _, data := range []string{
+		`{"kind":"Unknown"}`,
+		`{"kind":"Identifier","Nmae":"x"}`,
+		`{"kind":"UnaryExpression","Operator":"NOT_A_TOKEN"}`,
+		`{"kind":"FieldExpression","Field":{"kind":"ReturnStatement"}}`,
+		`{"kind":"Identifier","NamePos":"test.spl:1:1"}`,
+		`[1, 2]`,
+	} {
+		if _, err := ast.UnmarshalJSON([]byte(data), nil); err == nil {
+			t.Errorf("Expected error for %s", data)
+		}
+	}
+}
+
+func TestJSONDocComments(t *testing.T) {
+	file, fileInfo := parseTestSource(t, commentTestSource)
+
+	data, err := ast.MarshalJSON(file, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	node, err := ast.UnmarshalJSON(data, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := node.(*ast.File)
+
+	if doc := decoded.Declarations[0].(*ast.VariableDeclaration).Doc; doc == nil || doc != decoded.Comments[0] {
+		t.Errorf("Doc of counter is not the first comment group of the file")
+	}
+	if doc := decoded.Declarations[1].(*ast.FunctionDeclaration).Doc; doc == nil || doc != decoded.Comments[2] {
+		t.Errorf("Doc of check is not the third comment group of the file")
+	}
+
+	// The comment map of the decoded file associates the doc comment with the declaration
+	cmap := ast.NewCommentMap(fileInfo, decoded, decoded.Comments)
+	if groups := cmap[decoded.Declarations[1]]; len(groups) == 0 || groups[0] != decoded.Declarations[1].(*ast.FunctionDeclaration).Doc {
+		t.Errorf("Doc of check is not associated with it in the comment map: %v", groups)
+	}
+}
@@ -3,6 +3,7 @@ package ast
 import (
 	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
 
 	"github.com/Minnozz/gospl/token"
@@ -14,12 +15,7 @@ type printer struct {
 	depth    int
 }
 
-func (p *printer) Visit(n Node) {
-	if n == nil {
-		// Skip nil nodes
-		return
-	}
-
+func (p *printer) Visit(n Node) Visitor {
 	// Use reflection to get the type name without "*ast." prefix that %T adds
 	t := reflect.ValueOf(n).Elem().Type()
 	nodeType := t.Name()
@@ -35,6 +31,10 @@ func (p *printer) Visit(n Node) {
 	case *UnaryExpression:
 		info = nv.Operator.Print()
 	case *BinaryExpression:
+		info = nv.OperatorString()
+	case *AssignmentStatement:
+		info = nv.Operator.Print()
+	case *IncrementStatement:
 		info = nv.Operator.Print()
 	}
 
@@ -46,6 +46,8 @@ func (p *printer) Visit(n Node) {
 	p.lines = append(p.lines, line)
 
 	p.depth++
+
+	return p
 }
 
 func (p *printer) End(n Node) {
@@ -60,7 +62,63 @@ func Print(node Node, fileInfo *token.FileInfo) string {
 	return strings.Join(p.lines, "\n")
 }
 
+// SourceMode controls the parentheses around expressions in PrintSourceMode
+type SourceMode int
+
+const (
+	// Parentheses from the source, and those needed because of the precedence and associativity of the operators
+	MinimalParentheses SourceMode = iota
+	// Parentheses around every unary and binary expression, instead of those from the source
+	FullParentheses
+)
+
+// PrintSource prints node as source code, with parentheses where the source has them and where the tree needs them
 func PrintSource(node Node) string {
+	return PrintSourceMode(node, MinimalParentheses)
+}
+
+// PrintSourceMode prints node as source code.  The fixity of user-defined operators is taken from the fixity declarations
+// if node is a file; operators with an unknown fixity get parentheses around their operands.
+func PrintSourceMode(node Node, mode SourceMode) string {
+	p := &sourcePrinter{
+		mode:      mode,
+		operators: make(map[string]token.Fixity),
+	}
+	if file, ok := node.(*File); ok {
+		for _, decl := range file.Declarations {
+			if decl, ok := decl.(*FixityDeclaration); ok {
+				p.declare(decl)
+			}
+		}
+	}
+	return p.print(node)
+}
+
+type sourcePrinter struct {
+	mode      SourceMode
+	operators map[string]token.Fixity // User-defined operators
+}
+
+func (p *sourcePrinter) declare(decl *FixityDeclaration) {
+	level, err := strconv.Atoi(decl.Precedence.Value)
+	if err != nil {
+		return
+	}
+	fixity := token.Fixity{
+		Precedence: token.Precedence(level),
+	}
+	switch decl.Associativity {
+	case token.INFIXL:
+		fixity.Associativity = token.LeftAssociative
+	case token.INFIXR:
+		fixity.Associativity = token.RightAssociative
+	default:
+		fixity.Associativity = token.NonAssociative
+	}
+	p.operators[decl.Operator.Name] = fixity
+}
+
+func (p *sourcePrinter) print(node Node) string {
 	switch n := node.(type) {
 	// File
 	case *File:
@@ -70,23 +128,40 @@ func PrintSource(node Node) string {
 			if i > 0 {
 				out += "\n\n"
 			}
-			out += PrintSource(decl)
+			out += p.print(decl)
 		}
 		return out
 
 	// Declarations
+	case *ImportDeclaration:
+		return "import " + p.print(n.Path) + ";"
+	case *FixityDeclaration:
+		return n.Associativity.Print() + " " + p.print(n.Precedence) + " " + p.print(n.Operator) + " " + p.print(n.Function) + ";"
 	case *VariableDeclaration:
-		return PrintSource(n.Type) + " " + PrintSource(n.Name) + " = " + PrintSource(n.Initializer) + ";"
+		out := "var"
+		if n.Type != nil {
+			out = p.print(n.Type)
+		}
+		if n.Name != nil {
+			out += " " + p.print(n.Name)
+		} else {
+			out += " " + p.print(n.Pattern)
+		}
+		return out + " = " + p.print(n.Initializer) + ";"
 	case *FunctionDeclaration:
-		out := PrintSource(n.ReturnType) + " " + PrintSource(n.Name) + "(" + PrintSource(n.Parameters) + ") {\n"
+		out := ""
+		if n.Context != nil {
+			out += p.print(n.Context) + " "
+		}
+		out += p.print(n.ReturnType) + " " + p.print(n.Name) + "(" + p.print(n.Parameters) + ") {\n"
 		if len(n.Variables) > 0 {
 			for _, varDecl := range n.Variables {
-				out += indent(PrintSource(varDecl)) + "\n"
+				out += indent(p.print(varDecl)) + "\n"
 			}
 			out += "\n"
 		}
 		for _, stmt := range n.Statements {
-			out += indent(PrintSource(stmt)) + "\n"
+			out += indent(p.print(stmt)) + "\n"
 		}
 		out += "}"
 		return out
@@ -96,85 +171,398 @@ func PrintSource(node Node) string {
 			if i > 0 {
 				out += ", "
 			}
-			out += PrintSource(param)
+			out += p.print(param)
 		}
 		return out
 	case *FunctionParameter:
-		return PrintSource(n.Type) + " " + PrintSource(n.Name)
-	case *BadDeclaration:
-		return "/* BAD DECLARATION */"
-
-	// Expressions
-	case *LiteralExpression:
-		return n.Value
-	case *UnaryExpression:
-		return n.Operator.Print() + PrintSource(n.Operand)
-	case *BinaryExpression:
-		return PrintSource(n.Left) + " " + n.Operator.Print() + " " + PrintSource(n.Right)
-	case *FunctionCallExpression:
-		out := PrintSource(n.Name) + "("
-		for i, expr := range n.Arguments {
+		return p.print(n.Type) + " " + p.print(n.Name)
+	case *RecordDeclaration:
+		out := "record " + p.print(n.Name) + " {\n"
+		for _, field := range n.Fields {
+			out += indent(p.print(field)) + "\n"
+		}
+		out += "}"
+		return out
+	case *RecordField:
+		return p.print(n.Type) + " " + p.print(n.Name) + ";"
+	case *TypeDeclaration:
+		out := "type " + p.print(n.Name)
+		for _, param := range n.TypeParameters {
+			out += " " + p.print(param)
+		}
+		return out + " = " + p.print(n.Type) + ";"
+	case *DataDeclaration:
+		out := "data " + p.print(n.Name)
+		for _, param := range n.TypeParameters {
+			out += " " + p.print(param)
+		}
+		out += " ="
+		for i, constructor := range n.Constructors {
 			if i > 0 {
-				out += ", "
+				out += " |"
 			}
-			out += PrintSource(expr)
+			out += " " + p.print(constructor)
 		}
-		out += ")"
+		out += ";"
 		return out
-	case *ParenthesizedExpression:
-		return "(" + PrintSource(n.Expression) + ")"
-	case *TupleExpression:
-		return "(" + PrintSource(n.Left) + ", " + PrintSource(n.Right) + ")"
-	case *Identifier:
-		return n.Name
-	case *BadExpression:
-		return "/* BAD EXPRESSION */"
+	case *DataConstructor:
+		out := p.print(n.Name)
+		for _, field := range n.Fields {
+			out += " " + p.print(field)
+		}
+		return out
+	case *BadDeclaration:
+		return "/* BAD DECLARATION */"
 
 	// Statements
 	case *BlockStatement:
 		out := "{\n"
 		for _, stmt := range n.List {
-			out += indent(PrintSource(stmt)) + "\n"
+			out += indent(p.print(stmt)) + "\n"
 		}
 		out += "}"
 		return out
 	case *ReturnStatement:
 		out := "return"
 		if n.Value != nil {
-			out += " " + PrintSource(n.Value)
+			out += " " + p.print(n.Value)
 		}
 		out += ";"
 		return out
 	case *IfStatement:
-		out := "if(" + PrintSource(n.Condition) + ") " + PrintSource(n.Body)
+		out := "if(" + p.print(n.Condition) + ") " + p.print(n.Body)
 		if n.Else != nil {
-			out += " else " + PrintSource(n.Else)
+			out += " else " + p.print(n.Else)
 		}
 		return out
 	case *WhileStatement:
-		return "while(" + PrintSource(n.Condition) + ") " + PrintSource(n.Body)
+		return "while(" + p.print(n.Condition) + ") " + p.print(n.Body)
+	case *ForStatement:
+		out := "for(" + p.print(n.Variable) + " in " + p.print(n.Value)
+		if n.High != nil {
+			out += " .. " + p.print(n.High)
+		}
+		out += ") " + p.print(n.Body)
+		return out
+	case *BreakStatement:
+		return "break;"
+	case *ContinueStatement:
+		return "continue;"
+	case *MatchStatement:
+		out := "match(" + p.print(n.Value) + ") {\n"
+		for _, c := range n.Cases {
+			out += indent(p.print(c)) + "\n"
+		}
+		out += "}"
+		return out
+	case *MatchCase:
+		return p.print(n.Pattern) + " -> " + p.print(n.Body)
 	case *AssignmentStatement:
-		return PrintSource(n.Name) + " = " + PrintSource(n.Value) + ";"
+		return p.print(n.Target) + " " + n.Operator.Print() + " " + p.print(n.Value) + ";"
+	case *IncrementStatement:
+		return p.print(n.Target) + n.Operator.Print() + ";"
 	case *FunctionCallStatement:
-		return PrintSource(n.FunctionCall) + ";"
+		return p.print(n.FunctionCall) + ";"
 	case *BadStatement:
 		return "/* BAD STATEMENT */"
 
 	// Types
 	case *NamedType:
-		return PrintSource(n.Name)
+		out := p.print(n.Name)
+		for _, arg := range n.Arguments {
+			out += " " + p.print(arg)
+		}
+		return out
+	case *BasicType:
+		return p.print(n.Name)
+	case *TypeVariable:
+		return p.print(n.Name)
+	case *ParenthesizedType:
+		return "(" + p.print(n.Type) + ")"
+	case *TypeContext:
+		if n.RoundBracketOpen == token.NoPos {
+			return p.print(n.Constraints[0]) + " =>"
+		}
+		out := "("
+		for i, constraint := range n.Constraints {
+			if i > 0 {
+				out += ", "
+			}
+			out += p.print(constraint)
+		}
+		out += ") =>"
+		return out
 	case *TupleType:
-		return "(" + PrintSource(n.Left) + ", " + PrintSource(n.Right) + ")"
+		out := "("
+		for i, t := range n.Elements {
+			if i > 0 {
+				out += ", "
+			}
+			out += p.print(t)
+		}
+		out += ")"
+		return out
+	case *FunctionType:
+		out := "("
+		for i, param := range n.Parameters {
+			if i > 0 {
+				out += ", "
+			}
+			out += p.print(param)
+		}
+		if len(n.Parameters) > 0 {
+			out += " "
+		}
+		out += "-> " + p.print(n.Result) + ")"
+		return out
 	case *ListType:
-		return "[" + PrintSource(n.ElementType) + "]"
+		return "[" + p.print(n.ElementType) + "]"
 	case *BadType:
 		return "/* BAD TYPE */"
 
+	default:
+		return p.expr(node, noOperator)
+	}
+}
+
+// Precedence of the operator after an expression if there is none, or if it is a user-defined operator with an unknown
+// fixity
+const (
+	noOperator      token.Precedence = token.MinPrecedence - 1
+	unknownOperator token.Precedence = token.MaxPrecedence + 1
+)
+
+// Position of an operand
+type operandPosition int
+
+const (
+	leftOperand operandPosition = iota
+	rightOperand
+	unaryOperand
+	postfixOperand // Record or tuple in a field expression, or function in a function call
+)
+
+// expr prints an expression that is followed by a binary operator with precedence next
+func (p *sourcePrinter) expr(node Node, next token.Precedence) string {
+	node = p.unparen(node)
+
+	switch n := node.(type) {
+	case *LiteralExpression:
+		return n.Value
+	case *UnaryExpression:
+		fixity, _ := token.UnaryFixity(n.Operator)
+		out := n.Operator.Print() + p.operand(n.Operand, unaryOperand, fixity, true, next)
+		if p.mode == FullParentheses {
+			out = "(" + out + ")"
+		}
+		return out
+	case *BinaryExpression:
+		fixity, known := p.binaryFixity(n)
+		opPrec := unknownOperator
+		if known {
+			opPrec = fixity.Precedence
+		}
+		out := p.operand(n.Left, leftOperand, fixity, known, opPrec) + " " + n.OperatorString() + " " +
+			p.operand(n.Right, rightOperand, fixity, known, next)
+		if p.mode == FullParentheses {
+			out = "(" + out + ")"
+		}
+		return out
+	case *FunctionCallExpression:
+		out := p.operand(n.Function, postfixOperand, token.Fixity{}, true, noOperator) + "("
+		for i, expr := range n.Arguments {
+			if i > 0 {
+				out += ", "
+			}
+			out += p.print(expr)
+		}
+		out += ")"
+		return out
+	case *LambdaExpression:
+		out := "\\"
+		for i, param := range n.Parameters {
+			if i > 0 {
+				out += " "
+			}
+			out += p.print(param)
+		}
+		out += " -> " + p.print(n.Body)
+		return out
+	case *ParenthesizedExpression:
+		return "(" + p.print(n.Expression) + ")"
+	case *TupleExpression:
+		out := "("
+		for i, expr := range n.Elements {
+			if i > 0 {
+				out += ", "
+			}
+			out += p.print(expr)
+		}
+		out += ")"
+		return out
+	case *ListExpression:
+		if len(n.Elements) == 0 {
+			// "[]" is an empty list literal
+			return "[ ]"
+		}
+		out := "["
+		for i, expr := range n.Elements {
+			if i > 0 {
+				out += ", " + p.print(expr)
+			} else {
+				out += p.listHead(expr)
+			}
+		}
+		out += "]"
+		return out
+	case *RangeExpression:
+		return "[" + p.listHead(n.Low) + " .. " + p.print(n.High) + "]"
+	case *ComprehensionExpression:
+		out := "[" + p.listHead(n.Value) + " | "
+		for i, qualifier := range n.Qualifiers {
+			if i > 0 {
+				out += ", "
+			}
+			out += p.print(qualifier)
+		}
+		out += "]"
+		return out
+	case *ComprehensionGenerator:
+		return p.print(n.Pattern) + " <- " + p.print(n.List)
+	case *RecordExpression:
+		out := p.print(n.Name) + "{"
+		for i, expr := range n.Values {
+			if i > 0 {
+				out += ", "
+			}
+			out += p.print(expr)
+		}
+		out += "}"
+		return out
+	case *FieldExpression:
+		return p.operand(n.Expression, postfixOperand, token.Fixity{}, true, noOperator) + "." + p.print(n.Field)
+	case *Identifier:
+		return n.Name
+	case *BadExpression:
+		return "/* BAD EXPRESSION */"
+
 	default:
 		return "/* UNKNOWN AST NODE */"
 	}
 }
 
+// unparen leaves out the parentheses from the source in FullParentheses mode
+func (p *sourcePrinter) unparen(node Node) Node {
+	if p.mode == FullParentheses {
+		for {
+			paren, ok := node.(*ParenthesizedExpression)
+			if !ok {
+				break
+			}
+			node = paren.Expression
+		}
+	}
+	return node
+}
+
+func (p *sourcePrinter) binaryFixity(e *BinaryExpression) (token.Fixity, bool) {
+	if e.Operator == token.OPERATOR {
+		fixity, ok := p.operators[e.Symbol]
+		return fixity, ok
+	}
+	return token.BinaryFixity(e.Operator)
+}
+
+// operand prints an operand of an operator with the given fixity, in parentheses if needed.  known is false for a
+// user-defined operator with an unknown fixity.  next is the precedence of the operator after the operand.
+func (p *sourcePrinter) operand(e Expression, pos operandPosition, fixity token.Fixity, known bool,
+	next token.Precedence) string {
+	if p.needsParentheses(p.unparen(e), pos, fixity, known, next) {
+		return "(" + p.expr(e, noOperator) + ")"
+	}
+	return p.expr(e, next)
+}
+
+func (p *sourcePrinter) needsParentheses(e Node, pos operandPosition, fixity token.Fixity, known bool,
+	next token.Precedence) bool {
+	switch e := e.(type) {
+	case *LambdaExpression:
+		// The body extends as far as possible
+		return pos == postfixOperand || next != noOperator
+	case *UnaryExpression:
+		if p.mode == FullParentheses {
+			return false
+		}
+		// The operand swallows the next operator if it binds at least as tightly
+		operandFixity, _ := token.UnaryFixity(e.Operator)
+		return pos == postfixOperand || next != noOperator && next >= operandPrecedence(operandFixity)
+	case *BinaryExpression:
+		if p.mode == FullParentheses {
+			return false
+		}
+		operatorFixity, ok := p.binaryFixity(e)
+		if pos == postfixOperand || !ok || !known {
+			return true
+		}
+		switch pos {
+		case leftOperand:
+			return operatorFixity.Precedence < fixity.Precedence || operatorFixity.Precedence == fixity.Precedence &&
+				(operatorFixity.Associativity != token.LeftAssociative || fixity.Associativity != token.LeftAssociative)
+		case rightOperand:
+			return operatorFixity.Precedence < fixity.Precedence || operatorFixity.Precedence == fixity.Precedence &&
+				(operatorFixity.Associativity != token.RightAssociative || fixity.Associativity != token.RightAssociative)
+		case unaryOperand:
+			return operatorFixity.Precedence < operandPrecedence(fixity)
+		}
+	}
+	return false
+}
+
+// operandPrecedence returns the lowest precedence of a binary operator in the operand of a unary operator
+func operandPrecedence(fixity token.Fixity) token.Precedence {
+	if fixity.Associativity == token.LeftAssociative {
+		return fixity.Precedence + 1
+	}
+	return fixity.Precedence
+}
+
+// listHead puts the first element of a list, range or list comprehension in parentheses if it would be printed with a bitwise
+// or outside brackets, which would start the qualifiers of a list comprehension instead
+func (p *sourcePrinter) listHead(e Expression) string {
+	if p.containsBar(e, noOperator) {
+		return "(" + p.print(e) + ")"
+	}
+	return p.print(e)
+}
+
+// containsBar reports whether e is printed with a bitwise or outside brackets.  It follows expr to find the operands that
+// are printed without parentheses.
+func (p *sourcePrinter) containsBar(e Expression, next token.Precedence) bool {
+	switch e := p.unparen(e).(type) {
+	case *UnaryExpression:
+		if p.mode == FullParentheses {
+			return false
+		}
+		fixity, _ := token.UnaryFixity(e.Operator)
+		return !p.needsParentheses(p.unparen(e.Operand), unaryOperand, fixity, true, next) && p.containsBar(e.Operand, next)
+	case *BinaryExpression:
+		if p.mode == FullParentheses {
+			return false
+		}
+		fixity, known := p.binaryFixity(e)
+		opPrec := unknownOperator
+		if known {
+			opPrec = fixity.Precedence
+		}
+		return e.Operator == token.BAR ||
+			!p.needsParentheses(p.unparen(e.Left), leftOperand, fixity, known, opPrec) && p.containsBar(e.Left, opPrec) ||
+			!p.needsParentheses(p.unparen(e.Right), rightOperand, fixity, known, next) && p.containsBar(e.Right, next)
+	case *LambdaExpression:
+		return p.containsBar(e.Body, noOperator)
+	}
+	return false
+}
+
 func indent(s string) string {
 	return "\t" + strings.Replace(s, "\n", "\n\t", -1)
 }
@@ -0,0 +1,152 @@
+package ast_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Minnozz/gospl/ast"
+	"github.com/Minnozz/gospl/ast/astutil"
+)
+
+// stripParentheses removes the parentheses from the source, like a tree that is built or rewritten by a program
+func stripParentheses(node ast.Node) ast.Node {
+	return astutil.Apply(node, nil, func(c *astutil.Cursor) bool {
+		if paren, ok := c.Node().(*ast.ParenthesizedExpression); ok {
+			c.Replace(paren.Expression)
+		}
+		return true
+	})
+}
+
+// parseTestReturnValue parses the value of a return statement in a function after decls
+func parseTestReturnValue(t *testing.T, decls, src string) (*ast.File, ast.Expression) {
+	file, _ := parseTestSource(t, decls+"Int main() { return "+src+"; }")
+	fn := file.Declarations[len(file.Declarations)-1].(*ast.FunctionDeclaration)
+	return file, fn.Statements[0].(*ast.ReturnStatement).Value
+}
+
+func TestPrintSourceParentheses(t *testing.T) {
+	tests := []struct {
+		src      string
+		minimal  string
+		full     string
+		operator string // Fixity declaration for a user-defined operator
+	}{
+		{src: "(1 + 2) * 3", minimal: "(1 + 2) * 3", full: "((1 + 2) * 3)"},
+		{src: "1 + (2 * 3)", minimal: "1 + 2 * 3", full: "(1 + (2 * 3))"},
+		{src: "(1 - 2) - 3", minimal: "1 - 2 - 3", full: "((1 - 2) - 3)"},
+		{src: "1 - (2 - 3)", minimal: "1 - (2 - 3)", full: "(1 - (2 - 3))"},
+		{src: "(1 : 2) : xs", minimal: "(1 : 2) : xs", full: "((1 : 2) : xs)"},
+		{src: "1 : (2 : xs)", minimal: "1 : 2 : xs", full: "(1 : (2 : xs))"},
+		{src: "(a == b) == c", minimal: "a == b == c", full: "((a == b) == c)"},
+		{src: "(a && b) == c", minimal: "(a && b) == c", full: "((a && b) == c)"},
+		{src: "(-a) * b", minimal: "-a * b", full: "((-a) * b)"},
+		{src: "-(a * b)", minimal: "-(a * b)", full: "(-(a * b))"},
+		{src: "-(-a)", minimal: "--a", full: "(-(-a))"},
+		{src: "(!a) == b", minimal: "!a == b", full: "((!a) == b)"},
+		{src: "!(a == b)", minimal: "!(a == b)", full: "(!(a == b))"},
+		{src: "(!a) : xs", minimal: "(!a) : xs", full: "((!a) : xs)"},
+		{src: "!(a : xs)", minimal: "!a : xs", full: "(!(a : xs))"},
+		{src: "a + (!b) + c", minimal: "a + (!b) + c", full: "((a + (!b)) + c)"},
+		{src: "a && (!b)", minimal: "a && !b", full: "(a && (!b))"},
+		{src: "(a + b).fst", minimal: "(a + b).fst", full: "(a + b).fst"},
+		{src: "(-x).hd", minimal: "(-x).hd", full: "(-x).hd"},
+		{src: "-(x.hd)", minimal: "-x.hd", full: "(-x.hd)"},
+		{src: "(\\x -> x)(1)", minimal: "(\\x -> x)(1)", full: "(\\x -> x)(1)"},
+		{src: "(\\x -> x) == f", minimal: "(\\x -> x) == f", full: "((\\x -> x) == f)"},
+		{src: "f == (\\x -> x)", minimal: "f == \\x -> x", full: "(f == \\x -> x)"},
+		{src: "(f == (\\x -> x)) && b", minimal: "f == (\\x -> x) && b", full: "((f == (\\x -> x)) && b)"},
+		{src: "[(a | b) | a <- xs]", minimal: "[(a | b) | a <- xs]", full: "[(a | b) | a <- xs]"},
+		{src: "[(a | b), c]", minimal: "[(a | b), c]", full: "[(a | b), c]"},
+		{src: "[c, (a | b)]", minimal: "[c, a | b]", full: "[c, (a | b)]"},
+		{src: "[-(a | b)]", minimal: "[-(a | b)]", full: "[(-(a | b))]"},
+		{src: "[(-a) | b]", minimal: "[-a | b]", full: "[(-a) | b]"},
+		{src: "[(a | b) * c, d]", minimal: "[(a | b) * c, d]", full: "[((a | b) * c), d]"},
+		{src: "[((a & b) | c), d]", minimal: "[(a & b | c), d]", full: "[((a & b) | c), d]"},
+		{src: "[(\\z -> z | 1)]", minimal: "[(\\z -> z | 1)]", full: "[\\z -> (z | 1)]"},
+		{src: "[(\\z -> z | 1) .. 3]", minimal: "[(\\z -> z | 1) .. 3]", full: "[\\z -> (z | 1) .. 3]"},
+		{src: "[(\\z -> z | 1) | y <- ys]", minimal: "[(\\z -> z | 1) | y <- ys]", full: "[\\z -> (z | 1) | y <- ys]"},
+		{src: "[a + (\\z -> z | 1)]", minimal: "[(a + \\z -> z | 1)]", full: "[(a + \\z -> (z | 1))]"},
+		{src: "[(\\z -> (z | 1))(2)]", minimal: "[(\\z -> z | 1)(2)]", full: "[(\\z -> (z | 1))(2)]"},
+		{src: "f((1 + 2))", minimal: "f(1 + 2)", full: "f((1 + 2))"},
+
+		// User-defined operators
+		{src: "xs ++ (ys ++ zs)", minimal: "xs ++ ys ++ zs", full: "(xs ++ (ys ++ zs))", operator: "infixr 5 ++ append;"},
+		{src: "(xs ++ ys) ++ zs", minimal: "(xs ++ ys) ++ zs", full: "((xs ++ ys) ++ zs)", operator: "infixr 5 ++ append;"},
+		{src: "x : (xs ++ ys)", minimal: "x : xs ++ ys", full: "(x : (xs ++ ys))", operator: "infixr 5 ++ append;"},
+		{src: "(a <+> b) + c", minimal: "a <+> b + c", full: "((a <+> b) + c)", operator: "infixl 6 <+> add;"},
+		{src: "a <+> (b * c)", minimal: "a <+> b * c", full: "(a <+> (b * c))", operator: "infixl 6 <+> add;"},
+		{src: "(a === b) && c", minimal: "a === b && c", full: "((a === b) && c)", operator: "infix 3 === equal;"},
+		{src: "(a === b) == c", minimal: "(a === b) == c", full: "((a === b) == c)", operator: "infix 3 === equal;"},
+	}
+
+	for _, test := range tests {
+		file, expr := parseTestReturnValue(t, test.operator, test.src)
+		file = stripParentheses(file).(*ast.File)
+
+		for _, mode := range []ast.SourceMode{ast.MinimalParentheses, ast.FullParentheses} {
+			expected := test.minimal
+			if mode == ast.FullParentheses {
+				expected = test.full
+			}
+
+			// The fixity of user-defined operators is only known when printing the file
+			out := ast.PrintSourceMode(file, mode)
+			prefix := "return "
+			start := strings.Index(out, prefix) + len(prefix)
+			got := out[start : start+strings.Index(out[start:], ";\n")]
+			if got != expected {
+				t.Errorf("Printed %q as %q, expected %q", test.src, got, expected)
+				continue
+			}
+
+			// The printed expression must have the same tree
+			_, printed := parseTestReturnValue(t, test.operator, got)
+			stripped := stripParentheses(file).(*ast.File).Declarations
+			value := stripped[len(stripped)-1].(*ast.FunctionDeclaration).Statements[0].(*ast.ReturnStatement).Value
+			if d := ast.Diff(value, stripParentheses(printed), ast.EqualOptions{IgnorePositions: true}); d != "" {
+				t.Errorf("Printed %q as %q, which has a different tree: %s", test.src, got, d)
+			}
+		}
+
+		// Without the fixity declarations, the operands of user-defined operators get parentheses
+		if test.operator != "" {
+			if got := ast.PrintSource(stripParentheses(expr)); !strings.Contains(got, "(") {
+				t.Errorf("Printed %q with an unknown operator as %q, without parentheses", test.src, got)
+			}
+		}
+	}
+}
+
+// TestPrintSourceRoundTrip checks that printing the valid test programs gives the same trees, also when all parentheses
+// from the source are left out
+func TestPrintSourceRoundTrip(t *testing.T) {
+	files, err := filepath.Glob("../testdata/valid/*.spl")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("Error reading test directory: %v", err)
+	}
+
+	opts := ast.EqualOptions{IgnorePositions: true, IgnoreComments: true}
+	for _, filename := range files {
+		src, err := ioutil.ReadFile(filename)
+		if err != nil {
+			t.Fatalf("Error reading test %s: %v", filename, err)
+		}
+		file, _ := parseTestSource(t, string(src))
+
+		printed, _ := parseTestSource(t, ast.PrintSource(file))
+		if d := ast.Diff(file, printed, opts); d != "" {
+			t.Errorf("%s: printed source has a different tree: %s", filename, d)
+		}
+
+		stripped := stripParentheses(ast.Copy(file))
+		for _, mode := range []ast.SourceMode{ast.MinimalParentheses, ast.FullParentheses} {
+			printed, _ := parseTestSource(t, ast.PrintSourceMode(stripped, mode))
+			if d := ast.Diff(stripped, stripParentheses(printed), opts); d != "" {
+				t.Errorf("%s: printed source without parentheses (mode %d) has a different tree: %s", filename, mode, d)
+			}
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package ast
+
+import (
+	"github.com/Minnozz/gospl/token"
+)
+
+// ObjectKind describes what a named object is
+type ObjectKind int
+
+const (
+	Bad       ObjectKind = iota // For error handling
+	Builtin                     // Builtin function, type or constructor
+	GlobalVar                   // Global variable
+	LocalVar                    // Local variable, or variable bound by a pattern, loop or generator
+	Param                       // Parameter of a function or lambda
+	Fun                         // Function
+	TypeVar                     // Type variable
+	Typ                         // Record, data type or type synonym
+	Con                         // Data constructor
+)
+
+var objectKindStrings = [...]string{
+	Bad:       "bad",
+	Builtin:   "builtin",
+	GlobalVar: "global var",
+	LocalVar:  "local var",
+	Param:     "param",
+	Fun:       "func",
+	TypeVar:   "type var",
+	Typ:       "type",
+	Con:       "constructor",
+}
+
+func (kind ObjectKind) String() string { return objectKindStrings[kind] }
+
+// Object is a named variable, function, type or constructor.  Identifiers that refer to it have it in their Obj field.
+type Object struct {
+	Kind ObjectKind
+	Name string
+	Decl Node // Node that declares the object, e.g. *FunctionParameter or *MatchCase; nil for builtins
+}
+
+func NewObject(kind ObjectKind, name string, decl Node) *Object {
+	return &Object{
+		Kind: kind,
+		Name: name,
+		Decl: decl,
+	}
+}
+
+// Pos returns the position of the first identifier in the declaration of obj that refers to it, or token.NoPos if it is
+// unknown
+func (obj *Object) Pos() token.Pos {
+	pos := token.NoPos
+	if obj.Decl != nil {
+		Inspect(obj.Decl, func(n Node) bool {
+			if ident, ok := n.(*Identifier); ok && ident.Obj == obj {
+				pos = ident.Pos()
+			}
+			return pos == token.NoPos
+		})
+	}
+	return pos
+}
+
+// Scope holds the objects declared in a file, function, lambda, match case, loop or list comprehension.  Types and type
+// variables have their own namespace, so a data type and its constructor can have the same name.
+type Scope struct {
+	Outer   *Scope
+	Objects map[string]*Object // Variables, functions and constructors
+	Types   map[string]*Object // Types and type variables
+}
+
+func NewScope(outer *Scope) *Scope {
+	return &Scope{
+		Outer:   outer,
+		Objects: make(map[string]*Object),
+		Types:   make(map[string]*Object),
+	}
+}
+
+// Lookup returns the variable, function or constructor with the given name in s, or nil.  The outer scopes are not
+// searched.
+func (s *Scope) Lookup(name string) *Object {
+	return s.Objects[name]
+}
+
+// LookupType returns the type or type variable with the given name in s, or nil.  The outer scopes are not searched.
+func (s *Scope) LookupType(name string) *Object {
+	return s.Types[name]
+}
+
+// Insert adds obj to s, unless s already has an object with the same name in its namespace.  In that case, that object
+// is returned.
+func (s *Scope) Insert(obj *Object) (alt *Object) {
+	return insert(s.Objects, obj)
+}
+
+// InsertType is like Insert, for types and type variables
+func (s *Scope) InsertType(obj *Object) (alt *Object) {
+	return insert(s.Types, obj)
+}
+
+func insert(objects map[string]*Object, obj *Object) (alt *Object) {
+	if alt = objects[obj.Name]; alt == nil {
+		objects[obj.Name] = obj
+	}
+	return alt
+}
+
+// Universe is the outermost scope, with the builtin functions, types and constructors
+var Universe = NewScope(nil)
+
+func init() {
+	// isEmpty is spelled isempty in older programs, which access lists and tuples with head, tail, fst and snd instead of
+	// field selectors
+	for _, name := range []string{"print", "read", "isEmpty", "isempty", "head", "tail", "fst", "snd", "True", "False"} {
+		Universe.Insert(NewObject(Builtin, name, nil))
+	}
+	for _, name := range []string{"Int", "Bool", "Char", "Void"} {
+		Universe.InsertType(NewObject(Builtin, name, nil))
+	}
+}
@@ -57,15 +57,73 @@ type WhileStatement struct {
 func (s *WhileStatement) Pos() token.Pos { return s.While }
 func (s *WhileStatement) End() token.Pos { return s.Body.End() }
 
+type ForStatement struct {
+	For      token.Pos
+	Variable *Identifier
+	Value    Expression // List, or lo for a range
+	High     Expression // hi for a range; nil for a list
+	Body     Statement
+}
+
+func (s *ForStatement) Pos() token.Pos { return s.For }
+func (s *ForStatement) End() token.Pos { return s.Body.End() }
+
+type MatchStatement struct {
+	Match             token.Pos
+	Value             Expression
+	CurlyBracketOpen  token.Pos
+	Cases             []*MatchCase
+	CurlyBracketClose token.Pos
+}
+
+func (s *MatchStatement) Pos() token.Pos { return s.Match }
+func (s *MatchStatement) End() token.Pos { return s.CurlyBracketClose + 1 }
+
+type MatchCase struct {
+	Pattern Expression // Constructors, variables, literals, cons and tuples only
+	Arrow   token.Pos
+	Body    Statement
+}
+
+func (s *MatchCase) Pos() token.Pos { return s.Pattern.Pos() }
+func (s *MatchCase) End() token.Pos { return s.Body.End() }
+
+type BreakStatement struct {
+	Break     token.Pos
+	Semicolon token.Pos
+}
+
+func (s *BreakStatement) Pos() token.Pos { return s.Break }
+func (s *BreakStatement) End() token.Pos { return s.Semicolon + 1 }
+
+type ContinueStatement struct {
+	Continue  token.Pos
+	Semicolon token.Pos
+}
+
+func (s *ContinueStatement) Pos() token.Pos { return s.Continue }
+func (s *ContinueStatement) End() token.Pos { return s.Semicolon + 1 }
+
 type AssignmentStatement struct {
-	Name      *Identifier
+	Target    Expression  // *Identifier, *FieldExpression, or a tuple or cons pattern of those for destructuring
+	Operator  token.Token // IS, or PLUS_IS, MINUS_IS, MULTIPLY_IS, DIVIDE_IS or MODULO_IS for compound assignment
 	Value     Expression
 	Semicolon token.Pos
 }
 
-func (s *AssignmentStatement) Pos() token.Pos { return s.Name.Pos() }
+func (s *AssignmentStatement) Pos() token.Pos { return s.Target.Pos() }
 func (s *AssignmentStatement) End() token.Pos { return s.Semicolon + 1 }
 
+type IncrementStatement struct {
+	Target      Expression // *Identifier or *FieldExpression
+	OperatorPos token.Pos
+	Operator    token.Token // INCREMENT or DECREMENT
+	Semicolon   token.Pos
+}
+
+func (s *IncrementStatement) Pos() token.Pos { return s.Target.Pos() }
+func (s *IncrementStatement) End() token.Pos { return s.Semicolon + 1 }
+
 type FunctionCallStatement struct {
 	FunctionCall *FunctionCallExpression
 	Semicolon    token.Pos
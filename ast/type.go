@@ -15,23 +15,65 @@ type BadType struct {
 func (t *BadType) Pos() token.Pos { return t.From }
 func (t *BadType) End() token.Pos { return t.To }
 
+// NamedType is a record, data type or type synonym, possibly applied to type arguments
 type NamedType struct {
-	Name *Identifier
+	Name      *Identifier
+	Arguments []Type
 }
 
 func (t *NamedType) Pos() token.Pos { return t.Name.Pos() }
-func (t *NamedType) End() token.Pos { return t.Name.End() }
+func (t *NamedType) End() token.Pos {
+	if len(t.Arguments) > 0 {
+		return t.Arguments[len(t.Arguments)-1].End()
+	}
+	return t.Name.End()
+}
+
+// BasicType is one of the builtin types Int, Bool, Char and Void
+type BasicType struct {
+	Name *Identifier
+}
+
+func (t *BasicType) Pos() token.Pos { return t.Name.Pos() }
+func (t *BasicType) End() token.Pos { return t.Name.End() }
+
+// TypeVariable is a type name that starts with a lower case letter
+type TypeVariable struct {
+	Name *Identifier
+}
+
+func (t *TypeVariable) Pos() token.Pos { return t.Name.Pos() }
+func (t *TypeVariable) End() token.Pos { return t.Name.End() }
+
+type ParenthesizedType struct {
+	RoundBracketOpen  token.Pos
+	Type              Type
+	RoundBracketClose token.Pos
+}
+
+func (t *ParenthesizedType) Pos() token.Pos { return t.RoundBracketOpen }
+func (t *ParenthesizedType) End() token.Pos { return t.RoundBracketClose + 1 }
 
 type TupleType struct {
 	RoundBracketOpen  token.Pos
-	Left              Type
-	Right             Type
+	Elements          []Type // At least two
 	RoundBracketClose token.Pos
 }
 
 func (t *TupleType) Pos() token.Pos { return t.RoundBracketOpen }
 func (t *TupleType) End() token.Pos { return t.RoundBracketClose + 1 }
 
+type FunctionType struct {
+	RoundBracketOpen  token.Pos
+	Parameters        []Type
+	Arrow             token.Pos
+	Result            Type
+	RoundBracketClose token.Pos
+}
+
+func (t *FunctionType) Pos() token.Pos { return t.RoundBracketOpen }
+func (t *FunctionType) End() token.Pos { return t.RoundBracketClose + 1 }
+
 type ListType struct {
 	SquareBracketOpen  token.Pos
 	ElementType        Type
@@ -40,3 +82,19 @@ type ListType struct {
 
 func (t *ListType) Pos() token.Pos { return t.SquareBracketOpen }
 func (t *ListType) End() token.Pos { return t.SquareBracketClose + 1 }
+
+// TypeContext is a list of type class constraints on the type variables of a function (Eq t => or (Eq t, Ord u) =>)
+type TypeContext struct {
+	RoundBracketOpen  token.Pos // NoPos for a single constraint without brackets
+	Constraints       []Type    // Type classes applied to types, as *NamedType
+	RoundBracketClose token.Pos
+	DoubleArrow       token.Pos
+}
+
+func (c *TypeContext) Pos() token.Pos {
+	if c.RoundBracketOpen == token.NoPos {
+		return c.Constraints[0].Pos()
+	}
+	return c.RoundBracketOpen
+}
+func (c *TypeContext) End() token.Pos { return c.DoubleArrow + 2 }
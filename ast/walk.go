@@ -1,16 +1,17 @@
 package ast
 
+// Visitor is used by Walk.  Visit is called for every node before its children.  If it returns a visitor w, the children are
+// walked with w, followed by a call to w.End(n).  If it returns nil, the children and the call to End are skipped.
 type Visitor interface {
-	Visit(n Node)
+	Visit(n Node) (w Visitor)
 	End(n Node)
 }
 
 type VisitorFunc func(Node)
 
-func (f VisitorFunc) Visit(n Node) {
-	if n != nil {
-		f(n)
-	}
+func (f VisitorFunc) Visit(n Node) Visitor {
+	f(n)
+	return f
 }
 
 func (f VisitorFunc) End(n Node) {
@@ -21,9 +22,41 @@ func WalkFunc(n Node, v VisitorFunc) {
 	Walk(n, v)
 }
 
+// Inspector is a Visitor that calls Pre before and Post after visiting the children of a node.  The children are skipped if
+// Pre returns false.  Pre and Post may be nil.
+type Inspector struct {
+	Pre  func(Node) bool
+	Post func(Node)
+}
+
+func (i Inspector) Visit(n Node) Visitor {
+	if i.Pre != nil && !i.Pre(n) {
+		return nil
+	}
+	return i
+}
+
+func (i Inspector) End(n Node) {
+	if i.Post != nil {
+		i.Post(n)
+	}
+}
+
+// Inspect calls f for every node in depth-first order.  The children of a node are skipped if f returns false for it.
+func Inspect(n Node, f func(Node) bool) {
+	Walk(n, Inspector{Pre: f})
+}
+
+// Walk traverses the tree rooted at n in depth-first order.  Nil children are skipped.
 func Walk(n Node, v Visitor) {
+	if n == nil {
+		return
+	}
+
 	// Visit node itself
-	v.Visit(n)
+	if v = v.Visit(n); v == nil {
+		return
+	}
 
 	// Visit node children (if any)
 	switch nv := n.(type) {
@@ -34,11 +67,39 @@ func Walk(n Node, v Visitor) {
 		for _, ce := range nv.Comments {
 			Walk(ce, v)
 		}
+	case *CommentGroup:
+		for _, ce := range nv.List {
+			Walk(ce, v)
+		}
+	case *ImportDeclaration:
+		if nv.Doc != nil {
+			Walk(nv.Doc, v)
+		}
+		Walk(nv.Path, v)
+	case *FixityDeclaration:
+		if nv.Doc != nil {
+			Walk(nv.Doc, v)
+		}
+		Walk(nv.Precedence, v)
+		Walk(nv.Operator, v)
+		Walk(nv.Function, v)
 	case *VariableDeclaration:
+		if nv.Doc != nil {
+			Walk(nv.Doc, v)
+		}
 		Walk(nv.Type, v)
-		Walk(nv.Name, v)
+		if nv.Name != nil {
+			Walk(nv.Name, v)
+		}
+		Walk(nv.Pattern, v)
 		Walk(nv.Initializer, v)
 	case *FunctionDeclaration:
+		if nv.Doc != nil {
+			Walk(nv.Doc, v)
+		}
+		if nv.Context != nil {
+			Walk(nv.Context, v)
+		}
 		Walk(nv.ReturnType, v)
 		Walk(nv.Name, v)
 		Walk(nv.Parameters, v)
@@ -55,21 +116,86 @@ func Walk(n Node, v Visitor) {
 	case *FunctionParameter:
 		Walk(nv.Type, v)
 		Walk(nv.Name, v)
+	case *RecordDeclaration:
+		if nv.Doc != nil {
+			Walk(nv.Doc, v)
+		}
+		Walk(nv.Name, v)
+		for _, ce := range nv.Fields {
+			Walk(ce, v)
+		}
+	case *RecordField:
+		Walk(nv.Type, v)
+		Walk(nv.Name, v)
+	case *TypeDeclaration:
+		if nv.Doc != nil {
+			Walk(nv.Doc, v)
+		}
+		Walk(nv.Name, v)
+		for _, ce := range nv.TypeParameters {
+			Walk(ce, v)
+		}
+		Walk(nv.Type, v)
+	case *DataDeclaration:
+		if nv.Doc != nil {
+			Walk(nv.Doc, v)
+		}
+		Walk(nv.Name, v)
+		for _, ce := range nv.TypeParameters {
+			Walk(ce, v)
+		}
+		for _, ce := range nv.Constructors {
+			Walk(ce, v)
+		}
+	case *DataConstructor:
+		Walk(nv.Name, v)
+		for _, ce := range nv.Fields {
+			Walk(ce, v)
+		}
 	case *UnaryExpression:
 		Walk(nv.Operand, v)
 	case *BinaryExpression:
 		Walk(nv.Left, v)
 		Walk(nv.Right, v)
 	case *FunctionCallExpression:
-		Walk(nv.Name, v)
+		Walk(nv.Function, v)
 		for _, ce := range nv.Arguments {
 			Walk(ce, v)
 		}
+	case *LambdaExpression:
+		for _, ce := range nv.Parameters {
+			Walk(ce, v)
+		}
+		Walk(nv.Body, v)
 	case *ParenthesizedExpression:
 		Walk(nv.Expression, v)
 	case *TupleExpression:
-		Walk(nv.Left, v)
-		Walk(nv.Right, v)
+		for _, ce := range nv.Elements {
+			Walk(ce, v)
+		}
+	case *ListExpression:
+		for _, ce := range nv.Elements {
+			Walk(ce, v)
+		}
+	case *RangeExpression:
+		Walk(nv.Low, v)
+		Walk(nv.High, v)
+	case *ComprehensionExpression:
+		Walk(nv.Value, v)
+		for _, ce := range nv.Qualifiers {
+			Walk(ce, v)
+		}
+	case *ComprehensionGenerator:
+		Walk(nv.Pattern, v)
+		Walk(nv.List, v)
+	case *RecordExpression:
+		Walk(nv.Name, v)
+		for _, ce := range nv.Values {
+			Walk(ce, v)
+		}
+	case *FieldExpression:
+		Walk(nv.Expression, v)
+		Walk(nv.Field, v)
 	case *BlockStatement:
 		for _, ce := range nv.List {
 			Walk(ce, v)
@@ -83,16 +209,50 @@ func Walk(n Node, v Visitor) {
 	case *WhileStatement:
 		Walk(nv.Condition, v)
 		Walk(nv.Body, v)
+	case *ForStatement:
+		Walk(nv.Variable, v)
+		Walk(nv.Value, v)
+		Walk(nv.High, v)
+		Walk(nv.Body, v)
+	case *MatchStatement:
+		Walk(nv.Value, v)
+		for _, ce := range nv.Cases {
+			Walk(ce, v)
+		}
+	case *MatchCase:
+		Walk(nv.Pattern, v)
+		Walk(nv.Body, v)
 	case *AssignmentStatement:
-		Walk(nv.Name, v)
+		Walk(nv.Target, v)
 		Walk(nv.Value, v)
+	case *IncrementStatement:
+		Walk(nv.Target, v)
 	case *FunctionCallStatement:
 		Walk(nv.FunctionCall, v)
 	case *NamedType:
 		Walk(nv.Name, v)
+		for _, ce := range nv.Arguments {
+			Walk(ce, v)
+		}
+	case *BasicType:
+		Walk(nv.Name, v)
+	case *TypeVariable:
+		Walk(nv.Name, v)
+	case *ParenthesizedType:
+		Walk(nv.Type, v)
+	case *TypeContext:
+		for _, ce := range nv.Constraints {
+			Walk(ce, v)
+		}
 	case *TupleType:
-		Walk(nv.Left, v)
-		Walk(nv.Right, v)
+		for _, ce := range nv.Elements {
+			Walk(ce, v)
+		}
+	case *FunctionType:
+		for _, ce := range nv.Parameters {
+			Walk(ce, v)
+		}
+		Walk(nv.Result, v)
 	case *ListType:
 		Walk(nv.ElementType, v)
 	}
@@ -0,0 +1,85 @@
+package ast_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Minnozz/gospl/ast"
+	"github.com/Minnozz/gospl/parser"
+	"github.com/Minnozz/gospl/token"
+)
+
+func parseTestSource(t *testing.T, src string) (*ast.File, *token.FileInfo) {
+	fileInfo := &token.FileInfo{
+		Filename: "test.spl",
+	}
+
+	p := &parser.Parser{}
+	p.Init(fileInfo, []byte(src))
+	file := p.Parse()
+	for _, err := range p.Errors {
+		t.Fatal(err)
+	}
+	return file, fileInfo
+}
+
+func TestInspectPrune(t *testing.T) {
+	file, _ := parseTestSource(t, `
+Int global = 1;
+Int f(Int param) { Int local = param; return local; }
+Bool g() { return True; }
+`)
+
+	var names []string
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.Identifier:
+			names = append(names, n.Name)
+		case *ast.FunctionDeclaration:
+			// Only the name of a function, not its signature or body
+			names = append(names, n.Name.Name)
+			return false
+		}
+		return true
+	})
+
+	if got, want := strings.Join(names, " "), "Int global f g"; got != want {
+		t.Errorf("Inspected identifiers %q, expected %q", got, want)
+	}
+}
+
+func TestInspectorOrder(t *testing.T) {
+	file, _ := parseTestSource(t, "Int x = -(1 + y);")
+
+	var events []string
+	ast.Walk(file.Declarations[0], ast.Inspector{
+		Pre: func(n ast.Node) bool {
+			events = append(events, fmt.Sprintf("%T", n))
+			// Skip the type
+			_, isType := n.(*ast.BasicType)
+			return !isType
+		},
+		Post: func(n ast.Node) {
+			events = append(events, fmt.Sprintf("/%T", n))
+		},
+	})
+
+	want := []string{
+		"*ast.VariableDeclaration",
+		"*ast.BasicType",
+		"*ast.Identifier", "/*ast.Identifier",
+		"*ast.UnaryExpression",
+		"*ast.ParenthesizedExpression",
+		"*ast.BinaryExpression",
+		"*ast.LiteralExpression", "/*ast.LiteralExpression",
+		"*ast.Identifier", "/*ast.Identifier",
+		"/*ast.BinaryExpression",
+		"/*ast.ParenthesizedExpression",
+		"/*ast.UnaryExpression",
+		"/*ast.VariableDeclaration",
+	}
+	if got, want := strings.Join(events, " "), strings.Join(want, " "); got != want {
+		t.Errorf("Inspector calls:\n%s\nexpected:\n%s", got, want)
+	}
+}
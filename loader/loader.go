@@ -0,0 +1,230 @@
+package loader
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Minnozz/gospl/ast"
+	"github.com/Minnozz/gospl/parser"
+	"github.com/Minnozz/gospl/scanner"
+	"github.com/Minnozz/gospl/token"
+)
+
+// Extension of SPL source files; it is left out of import paths
+const Extension = ".spl"
+
+type File struct {
+	Filename string
+	FileInfo *token.FileInfo
+	AST      *ast.File
+	Imports  []*File
+}
+
+// Fixities returns the fixity declarations of f, which declare operators for the files that import f
+func (f *File) Fixities() []*ast.FixityDeclaration {
+	var fixities []*ast.FixityDeclaration
+	for _, decl := range f.AST.Declarations {
+		if fixity, ok := decl.(*ast.FixityDeclaration); ok {
+			fixities = append(fixities, fixity)
+		}
+	}
+	return fixities
+}
+
+// Program is a main file together with all files it imports, directly or indirectly.
+type Program struct {
+	Main  *File
+	Files []*File // Every file comes after the files it imports; Main is last
+}
+
+type Loader struct {
+	// Directories to search for imported files that are not next to the importing file
+	SearchPath []string
+
+	Errors scanner.ErrorList
+
+	program *Program
+	files   map[string]*File // Absolute filename => file
+	loading []*File          // Files of which the imports are being loaded
+}
+
+// Load parses the file with the given name and every file it imports.  Imported files are parsed only once.  Parse errors
+// and import errors are added to l.Errors; the returned error is only set if the main file cannot be read.
+func (l *Loader) Load(filename string) (*Program, error) {
+	src, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	l.program = &Program{}
+	l.files = make(map[string]*File)
+	l.loading = nil
+
+	l.program.Main = l.parseFile(filename, src)
+
+	return l.program, nil
+}
+
+func (l *Loader) parseFile(filename string, src []byte) *File {
+	f := &File{
+		Filename: filename,
+		FileInfo: &token.FileInfo{
+			Filename: filename,
+		},
+	}
+	l.files[absolute(filename)] = f
+
+	// The imports are loaded before the rest of the file is parsed, because the file can use the operators they declare.
+	// Syntax errors in the imports are reported by the full parse below.
+	p := &parser.Parser{Mode: parser.ImportsOnly}
+	p.Init(f.FileInfo, src)
+	l.loading = append(l.loading, f)
+	for _, imp := range p.Parse().Imports {
+		if dep := l.importFile(f, imp); dep != nil {
+			f.Imports = append(f.Imports, dep)
+		}
+	}
+	l.loading = l.loading[:len(l.loading)-1]
+
+	// The lines are scanned again, so a fresh FileInfo is needed
+	f.FileInfo = &token.FileInfo{
+		Filename: filename,
+	}
+	p = &parser.Parser{Mode: parser.DeclarationErrors}
+	p.Init(f.FileInfo, src)
+	for _, dep := range f.Imports {
+		for _, decl := range dep.Fixities() {
+			p.DeclareOperator(decl)
+		}
+	}
+	f.AST = p.Parse()
+	l.Errors = append(l.Errors, p.Errors...)
+
+	l.resolveImports(f)
+
+	l.program.Files = append(l.program.Files, f)
+
+	return f
+}
+
+func (l *Loader) importFile(from *File, imp *ast.ImportDeclaration) *File {
+	path, err := strconv.Unquote(imp.Path.Value)
+	if err != nil || path == "" {
+		l.error(from, imp.Path.Pos(), "invalid import path "+imp.Path.Value)
+		return nil
+	}
+
+	filename, ok := l.resolve(from, path)
+	if !ok {
+		l.error(from, imp.Path.Pos(), "cannot find import "+imp.Path.Value)
+		return nil
+	}
+
+	if f, ok := l.files[absolute(filename)]; ok {
+		for i, loading := range l.loading {
+			if loading == f {
+				var cycle []string
+				for _, g := range l.loading[i:] {
+					cycle = append(cycle, g.Filename)
+				}
+				cycle = append(cycle, f.Filename)
+				l.error(from, imp.Path.Pos(), "import cycle: "+strings.Join(cycle, " -> "))
+				return nil
+			}
+		}
+
+		// Already loaded
+		return f
+	}
+
+	src, err := ioutil.ReadFile(filename)
+	if err != nil {
+		l.error(from, imp.Path.Pos(), err.Error())
+		return nil
+	}
+
+	return l.parseFile(filename, src)
+}
+
+// resolveImports resolves the identifiers of f that are not declared in f itself.  Every top-level declaration of a file
+// is exported, but a file only sees the declarations of the files it imports directly; if more than one import declares
+// a name, the first one is used.  Names that are still undefined are reported, unless some import of f failed to load.
+func (l *Loader) resolveImports(f *File) {
+	imported := ast.NewScope(ast.Universe)
+	for _, dep := range f.Imports {
+		for _, obj := range dep.AST.Scope.Objects {
+			imported.Insert(obj)
+		}
+		for _, obj := range dep.AST.Scope.Types {
+			imported.InsertType(obj)
+		}
+	}
+	f.AST.Scope.Outer = imported
+
+	// Unresolved type names are told apart from other names by where they appear
+	types := make(map[*ast.Identifier]bool)
+	typeVariables := make(map[*ast.Identifier]bool)
+	ast.Inspect(f.AST, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.NamedType:
+			types[n.Name] = true
+		case *ast.BasicType:
+			types[n.Name] = true
+		case *ast.RecordExpression:
+			types[n.Name] = true
+		case *ast.TypeVariable:
+			typeVariables[n.Name] = true
+		}
+		return true
+	})
+
+	complete := len(f.Imports) == len(f.AST.Imports)
+	var unresolved []*ast.Identifier
+	for _, ident := range f.AST.Unresolved {
+		if typeVariables[ident] {
+			// Type variables are not imported
+			unresolved = append(unresolved, ident)
+			continue
+		}
+		lookup := imported.Lookup
+		if types[ident] {
+			lookup = imported.LookupType
+		}
+		if obj := lookup(ident.Name); obj != nil {
+			ident.Obj = obj
+			continue
+		}
+		if complete {
+			l.error(f, ident.Pos(), "undefined: "+ident.Name)
+		}
+		unresolved = append(unresolved, ident)
+	}
+	f.AST.Unresolved = unresolved
+}
+
+// resolve returns the name of the file for an import path.  The directory of the importing file is searched first, followed by
+// the directories in the search path.
+func (l *Loader) resolve(from *File, path string) (string, bool) {
+	dirs := append([]string{filepath.Dir(from.Filename)}, l.SearchPath...)
+	for _, dir := range dirs {
+		filename := filepath.Join(dir, filepath.FromSlash(path)+Extension)
+		if info, err := os.Stat(filename); err == nil && !info.IsDir() {
+			return filename, true
+		}
+	}
+	return "", false
+}
+
+func (l *Loader) error(f *File, pos token.Pos, msg string) {
+	l.Errors.Add(f.FileInfo.Position(pos), msg)
+}
+
+func absolute(filename string) string {
+	if abs, err := filepath.Abs(filename); err == nil {
+		return abs
+	}
+	return filepath.Clean(filename)
+}
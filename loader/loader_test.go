@@ -0,0 +1,127 @@
+package loader
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Minnozz/gospl/ast"
+)
+
+const testDir = "../testdata/modules"
+
+func loadTestFile(t *testing.T, name string) (*Loader, *Program) {
+	l := &Loader{
+		SearchPath: []string{filepath.Join(testDir, "lib")},
+	}
+	program, err := l.Load(filepath.Join(testDir, name))
+	if err != nil {
+		t.Fatalf("Error loading %s: %v", name, err)
+	}
+	return l, program
+}
+
+func TestLoaderImports(t *testing.T) {
+	l, program := loadTestFile(t, "main.spl")
+	for _, err := range l.Errors {
+		t.Error(err)
+	}
+
+	// pairs.spl is imported twice, but must only be loaded once
+	var names []string
+	for _, f := range program.Files {
+		names = append(names, filepath.Base(f.Filename))
+	}
+	if got, want := strings.Join(names, " "), "pairs.spl lists.spl main.spl"; got != want {
+		t.Errorf("Loaded files %q, expected %q", got, want)
+	}
+
+	if program.Main != program.Files[len(program.Files)-1] {
+		t.Errorf("Main file is not the last file")
+	}
+	if len(program.Main.Imports) != 2 || program.Main.Imports[1] != program.Files[0] {
+		t.Errorf("Imports of main file are not resolved to the loaded files")
+	}
+}
+
+func TestLoaderCycle(t *testing.T) {
+	l, _ := loadTestFile(t, "cycle_a.spl")
+	if len(l.Errors) != 1 {
+		t.Fatalf("Expected 1 error, got %v", l.Errors)
+	}
+	err := l.Errors[0]
+	if filepath.Base(err.Pos.Filename) != "cycle_b.spl" || err.Pos.Line != 1 || !strings.Contains(err.Msg, "import cycle") {
+		t.Errorf("Expected import cycle error in cycle_b.spl:1, got %v", err)
+	}
+}
+
+func TestLoaderMissing(t *testing.T) {
+	l, program := loadTestFile(t, "missing.spl")
+	if len(l.Errors) != 1 {
+		t.Fatalf("Expected 1 error, got %v", l.Errors)
+	}
+	err := l.Errors[0]
+	if filepath.Base(err.Pos.Filename) != "missing.spl" || err.Pos.Line != 2 || err.Pos.Column != 8 {
+		t.Errorf("Expected error at missing.spl:2:8, got %v", err)
+	}
+	if len(program.Main.Imports) != 1 {
+		t.Errorf("Expected 1 resolved import, got %d", len(program.Main.Imports))
+	}
+}
+
+func TestLoaderResolveImports(t *testing.T) {
+	l := &Loader{
+		SearchPath: []string{testDir, filepath.Join(testDir, "lib")},
+	}
+	program, err := l.Load("../testdata/valid/test36.spl")
+	if err != nil {
+		t.Fatalf("Error loading test36.spl: %v", err)
+	}
+	for _, err := range l.Errors {
+		t.Error(err)
+	}
+
+	if len(program.Main.AST.Unresolved) != 0 {
+		t.Errorf("Expected no unresolved names, got %v", program.Main.AST.Unresolved)
+	}
+	length := program.Main.AST.Scope.Outer.Lookup("length")
+	if length == nil || length.Decl != program.Files[1].AST.Declarations[1] {
+		t.Errorf("length is not resolved to its declaration in lists.spl")
+	}
+}
+
+func TestLoaderUndefined(t *testing.T) {
+	l, _ := loadTestFile(t, "undefined.spl")
+
+	// swap is declared in pairs.spl, which is only imported indirectly
+	expected := []string{
+		"undefined.spl:5:17: undefined: swap",
+		"undefined.spl:6:30: undefined: missing",
+	}
+	if len(l.Errors) != len(expected) {
+		t.Fatalf("Expected %d errors, got %v", len(expected), l.Errors)
+	}
+	for i, err := range l.Errors {
+		if got := filepath.Base(err.Error()); got != expected[i] {
+			t.Errorf("Expected error %q, got %q", expected[i], got)
+		}
+	}
+}
+
+func TestLoaderImportedOperator(t *testing.T) {
+	l, program := loadTestFile(t, "operators.spl")
+	for _, err := range l.Errors {
+		t.Error(err)
+	}
+
+	// <+> is declared right-associative in ops.spl
+	main := program.Main.AST.Declarations[1].(*ast.FunctionDeclaration)
+	value := main.Statements[0].(*ast.ReturnStatement).Value
+	outer, ok := value.(*ast.BinaryExpression)
+	if !ok || outer.Symbol != "<+>" {
+		t.Fatalf("Expected <+> expression, got %#v", value)
+	}
+	if inner, ok := outer.Right.(*ast.BinaryExpression); !ok || inner.Symbol != "<+>" {
+		t.Errorf("Expected <+> expression on the right, got %#v", outer.Right)
+	}
+}
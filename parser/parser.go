@@ -1,18 +1,46 @@
 package parser
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
 	"github.com/Minnozz/gospl/ast"
 	"github.com/Minnozz/gospl/scanner"
 	"github.com/Minnozz/gospl/token"
 )
 
+// A Mode is a set of flags that enable optional parser checks.
+type Mode uint
+
+const (
+	DeclarationErrors Mode = 1 << iota // Report names that are declared twice in the same scope
+	ImportsOnly                        // Stop parsing after the import declarations
+)
+
 type Parser struct {
+	Mode   Mode // Set before calling Init
 	Errors scanner.ErrorList
 
 	fileInfo *token.FileInfo
 	scanner  scanner.Scanner
 
-	comments []*ast.Comment
+	comments    []*ast.CommentGroup
+	leadComment *ast.CommentGroup // Comment group on the lines directly above the current token
+
+	// Builtin operators and operators declared so far
+	operators *OperatorTable
+
+	// Number of loops around the statement being parsed
+	loopDepth int
+
+	// Whether "|" ends the expression being parsed instead of being a bitwise or, in the first element of a list expression
+	barEndsExpression bool
+
+	// Whether "<-" ends the expression being parsed instead of being "<" followed by unary minus, in a comprehension qualifier
+	leftArrowEndsExpression bool
 
 	// Current scanner token
 	pos token.Pos
@@ -22,22 +50,143 @@ type Parser struct {
 
 func (p *Parser) Init(fileInfo *token.FileInfo, src []byte) {
 	p.fileInfo = fileInfo
+	p.operators = NewOperatorTable()
 	p.scanner.Init(fileInfo, src, func(pos token.Position, msg string) {
 		p.Errors.Add(pos, msg)
 	})
 
 	p.next()
 }
+
+// DeclareOperator declares the operator of a fixity declaration from another file, e.g. an imported one.  It must be
+// called after Init and before Parse.
+func (p *Parser) DeclareOperator(decl *ast.FixityDeclaration) {
+	if level, err := strconv.Atoi(decl.Precedence.Value); err == nil && declarable(decl.Operator.Name) {
+		p.declareOperator(decl.Operator.Name, decl.Associativity, level)
+	}
+}
+
 func (p *Parser) Parse() *ast.File {
 	var declarations []ast.Declaration
+	var imports []*ast.ImportDeclaration
 	for p.tok != token.EOF {
-		declarations = append(declarations, p.parseDeclaration())
+		if p.Mode&ImportsOnly != 0 && p.tok != token.IMPORT {
+			break
+		}
+		doc := p.leadComment
+		decl := p.parseDeclaration()
+		setDoc(decl, doc)
+		p.checkVoid(decl)
+		if imp, ok := decl.(*ast.ImportDeclaration); ok {
+			if len(imports) < len(declarations) {
+				p.error(imp.Pos(), "imports must appear before other declarations")
+			}
+			imports = append(imports, imp)
+		}
+		declarations = append(declarations, decl)
 	}
 
-	return &ast.File{
+	p.checkTypeSynonymCycles(declarations)
+
+	file := &ast.File{
 		Declarations: declarations,
+		Imports:      imports,
 		Comments:     p.comments,
 	}
+	p.resolve(file)
+
+	return file
+}
+
+// checkVoid reports uses of Void other than as the return type of a function or a function type
+func (p *Parser) checkVoid(decl ast.Declaration) {
+	returnTypes := make(map[ast.Type]bool)
+	ast.Inspect(decl, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FunctionDeclaration:
+			returnTypes[unparenType(n.ReturnType)] = true
+		case *ast.FunctionType:
+			returnTypes[unparenType(n.Result)] = true
+		case *ast.BasicType:
+			if n.Name.Name == "Void" && !returnTypes[n] {
+				p.error(n.Pos(), "Void can only be used as a return type")
+			}
+		}
+		return true
+	})
+}
+
+// unparenType returns t without the brackets around it
+func unparenType(t ast.Type) ast.Type {
+	for {
+		paren, ok := t.(*ast.ParenthesizedType)
+		if !ok {
+			return t
+		}
+		t = paren.Type
+	}
+}
+
+// checkTypeSynonymCycles reports type synonyms that would expand to themselves.  Only synonyms declared in this file are
+// checked; a cycle through a data type is allowed.
+func (p *Parser) checkTypeSynonymCycles(declarations []ast.Declaration) {
+	var synonyms []*ast.TypeDeclaration
+	byName := make(map[string]*ast.TypeDeclaration)
+	for _, decl := range declarations {
+		if synonym, ok := decl.(*ast.TypeDeclaration); ok {
+			synonyms = append(synonyms, synonym)
+			byName[synonym.Name.Name] = synonym
+		}
+	}
+
+	const (
+		unchecked = iota
+		checking
+		checked
+	)
+	state := make(map[*ast.TypeDeclaration]int)
+	var path []*ast.TypeDeclaration
+
+	var check func(synonym *ast.TypeDeclaration)
+	check = func(synonym *ast.TypeDeclaration) {
+		switch state[synonym] {
+		case checking:
+			var names []string
+			for i := len(path) - 1; i >= 0; i-- {
+				names = append([]string{path[i].Name.Name}, names...)
+				if path[i] == synonym {
+					break
+				}
+			}
+			names = append(names, synonym.Name.Name)
+			p.error(synonym.Name.Pos(), "invalid recursive type synonym: "+strings.Join(names, " -> "))
+			return
+		case checked:
+			return
+		}
+
+		state[synonym] = checking
+		path = append(path, synonym)
+
+		params := make(map[string]bool)
+		for _, param := range synonym.TypeParameters {
+			params[param.Name] = true
+		}
+		ast.WalkFunc(synonym.Type, func(n ast.Node) {
+			if t, ok := n.(*ast.NamedType); ok && !params[t.Name.Name] {
+				if used, ok := byName[t.Name.Name]; ok {
+					check(used)
+				}
+			}
+		})
+
+		path = path[:len(path)-1]
+		state[synonym] = checked
+	}
+
+	for _, synonym := range synonyms {
+		check(synonym)
+	}
 }
 
 func (p *Parser) nextToken() {
@@ -46,11 +195,45 @@ func (p *Parser) nextToken() {
 }
 
 func (p *Parser) next() {
+	p.leadComment = nil
+
+	prev := p.pos
 	p.nextToken()
 
 	// Automatically parse comments outside of the normal AST
-	for p.tok == token.COMMENT {
-		p.comments = append(p.comments, p.parseComment())
+	if p.tok == token.COMMENT {
+		if p.line(p.pos) == p.line(prev) {
+			// Comment at the end of a line with code; only comments on the same line belong to its group
+			p.comments = append(p.comments, p.parseCommentGroup(0))
+		}
+
+		var group *ast.CommentGroup
+		for p.tok == token.COMMENT {
+			group = p.parseCommentGroup(1)
+			p.comments = append(p.comments, group)
+		}
+		if group != nil && p.line(group.End())+1 == p.line(p.pos) && p.tok != token.EOF {
+			p.leadComment = group
+		}
+	}
+}
+
+func (p *Parser) line(pos token.Pos) int {
+	return p.fileInfo.Position(pos).Line
+}
+
+// parseCommentGroup parses comments that start at most n lines after the end of the previous comment
+func (p *Parser) parseCommentGroup(n int) *ast.CommentGroup {
+	var list []*ast.Comment
+	endLine := p.line(p.pos)
+	for p.tok == token.COMMENT && p.line(p.pos) <= endLine+n {
+		comment := p.parseComment()
+		list = append(list, comment)
+		endLine = p.line(comment.End())
+	}
+
+	return &ast.CommentGroup{
+		List: list,
 	}
 }
 
@@ -62,7 +245,7 @@ func (p *Parser) parseComment() *ast.Comment {
 	} else {
 		p.errorExpected(p.pos, "comment")
 	}
-	// Don't call p.next() because we are already in the for loop inside next().
+	// Don't call p.next() because we are already in the for loop inside parseCommentGroup().
 	p.nextToken()
 
 	return &ast.Comment{
@@ -93,16 +276,78 @@ func (p *Parser) expect(tok token.Token) token.Pos {
 	return pos
 }
 
+func setDoc(decl ast.Declaration, doc *ast.CommentGroup) {
+	switch d := decl.(type) {
+	case *ast.ImportDeclaration:
+		d.Doc = doc
+	case *ast.FixityDeclaration:
+		d.Doc = doc
+	case *ast.VariableDeclaration:
+		d.Doc = doc
+	case *ast.FunctionDeclaration:
+		d.Doc = doc
+	case *ast.RecordDeclaration:
+		d.Doc = doc
+	case *ast.TypeDeclaration:
+		d.Doc = doc
+	case *ast.DataDeclaration:
+		d.Doc = doc
+	}
+}
+
 func (p *Parser) parseDeclaration() ast.Declaration {
 	pos := p.pos
+
+	switch p.tok {
+	case token.IMPORT:
+		return p.parseImportDeclaration()
+	case token.RECORD:
+		return p.parseRecordDeclaration()
+	case token.TYPE:
+		return p.parseTypeDeclaration()
+	case token.DATA:
+		return p.parseDataDeclaration()
+	case token.INFIX, token.INFIXL, token.INFIXR:
+		return p.parseFixityDeclaration()
+	case token.VAR:
+		return p.parseVarDeclaration()
+	}
+
+	var context *ast.TypeContext
 	t := p.parseType()
+	if p.tok == token.DOUBLE_ARROW {
+		// Constraints in brackets ((Eq t, Ord u) => ...)
+		context = p.continueTypeContext(t)
+		t = p.parseType()
+	}
 	name := p.parseIdentifier()
+	if context == nil && p.tok == token.DOUBLE_ARROW {
+		// Single constraint (Eq t => ...), which was parsed as a type and a name
+		var constraint ast.Type = t
+		if class, ok := t.(*ast.NamedType); ok && len(class.Arguments) == 0 {
+			constraint = &ast.NamedType{
+				Name: class.Name,
+				Arguments: []ast.Type{
+					newTypeName(name),
+				},
+			}
+		}
+		context = p.continueTypeContext(constraint)
+		t = p.parseType()
+		name = p.parseIdentifier()
+	}
 
 	switch p.tok {
 	case token.IS:
+		if context != nil {
+			p.error(context.Pos(), "constraints are only allowed on function declarations")
+		}
 		return p.continueVariableDeclaration(t, name)
 	case token.ROUND_BRACKET_OPEN:
-		return p.continueFunctionDeclaration(t, name)
+		decl := p.continueFunctionDeclaration(t, name)
+		decl.Context = context
+		p.checkConstrainedTypeVariables(decl)
+		return decl
 	default:
 		p.errorExpected(p.pos, "declaration")
 		p.next()
@@ -113,32 +358,339 @@ func (p *Parser) parseDeclaration() ast.Declaration {
 	}
 }
 
+func (p *Parser) parseImportDeclaration() *ast.ImportDeclaration {
+	pos := p.expect(token.IMPORT)
+
+	path := &ast.LiteralExpression{
+		ValuePos: p.pos,
+		Kind:     token.STRING,
+	}
+	if p.tok == token.STRING {
+		path.Value = p.lit
+		p.next()
+	} else {
+		p.expect(token.STRING)
+	}
+
+	end := p.expect(token.SEMICOLON)
+
+	return &ast.ImportDeclaration{
+		Import:    pos,
+		Path:      path,
+		Semicolon: end,
+	}
+}
+
+// parseFixityDeclaration parses a declaration of a user-defined binary operator (infixr 5 ++ append;)
+func (p *Parser) parseFixityDeclaration() ast.Declaration {
+	pos, assocTok := p.pos, p.tok
+	p.next()
+
+	if p.tok != token.INTEGER {
+		p.errorExpected(p.pos, "precedence")
+		for p.tok != token.SEMICOLON && p.tok != token.EOF {
+			p.next()
+		}
+		p.next()
+
+		return &ast.BadDeclaration{
+			From: pos,
+			To:   p.pos,
+		}
+	}
+
+	prec := &ast.LiteralExpression{
+		ValuePos: p.pos,
+		Kind:     token.INTEGER,
+		Value:    p.lit,
+	}
+	level, err := strconv.Atoi(prec.Value)
+	if err != nil || token.Precedence(level) < token.MinPrecedence || token.Precedence(level) > token.MaxPrecedence {
+		p.error(prec.Pos(), fmt.Sprintf("precedence must be between %d and %d", token.MinPrecedence, token.MaxPrecedence))
+	}
+
+	// The operator is not declared yet, so it would be scanned as separate builtin tokens by p.next()
+	p.pos, p.tok, p.lit = p.scanner.ScanOperator()
+	op := &ast.Identifier{
+		NamePos: p.pos,
+		Name:    p.lit,
+	}
+	if p.tok == token.OPERATOR {
+		if !declarable(op.Name) {
+			p.error(op.Pos(), "cannot declare builtin operator or comment "+op.Name)
+		} else {
+			p.declareOperator(op.Name, assocTok, level)
+		}
+	}
+	p.next()
+
+	function := p.parseIdentifier()
+	end := p.expect(token.SEMICOLON)
+
+	return &ast.FixityDeclaration{
+		Fixity:        pos,
+		Associativity: assocTok,
+		Precedence:    prec,
+		Operator:      op,
+		Function:      function,
+		Semicolon:     end,
+	}
+}
+
+// declarable reports whether op can be declared by a fixity declaration.  ++ can be declared, because the builtin x++ is
+// a statement instead of an operator in expressions.
+func declarable(op string) bool {
+	builtin := token.LookupSymbol(op)
+	comment := strings.HasPrefix(op, "//") || strings.HasPrefix(op, "/*")
+	return op != "" && !comment && (builtin == token.INVALID || builtin == token.INCREMENT)
+}
+
+// declareOperator adds a user-defined binary operator to the operator table and the scanner.  assocTok is INFIX, INFIXL or
+// INFIXR.
+func (p *Parser) declareOperator(op string, assocTok token.Token, level int) {
+	assoc := token.LeftAssociative
+	switch assocTok {
+	case token.INFIXR:
+		assoc = token.RightAssociative
+	case token.INFIX:
+		assoc = token.NonAssociative
+	}
+	p.operators.Declare(op, token.Fixity{Precedence: token.Precedence(level), Associativity: assoc})
+	if token.LookupSymbol(op) == token.INVALID {
+		p.scanner.DeclareOperator(op)
+	}
+}
+
+func (p *Parser) parseRecordDeclaration() *ast.RecordDeclaration {
+	pos := p.expect(token.RECORD)
+	name := p.parseIdentifier()
+	open := p.expect(token.CURLY_BRACKET_OPEN)
+
+	var fields []*ast.RecordField
+	for p.tok != token.CURLY_BRACKET_CLOSE && p.tok != token.EOF {
+		fields = append(fields, p.parseRecordField())
+	}
+
+	end := p.expect(token.CURLY_BRACKET_CLOSE)
+
+	return &ast.RecordDeclaration{
+		Record:            pos,
+		Name:              name,
+		CurlyBracketOpen:  open,
+		Fields:            fields,
+		CurlyBracketClose: end,
+	}
+}
+
+func (p *Parser) parseRecordField() *ast.RecordField {
+	t := p.parseType()
+	name := p.parseIdentifier()
+	end := p.expect(token.SEMICOLON)
+
+	return &ast.RecordField{
+		Type:      t,
+		Name:      name,
+		Semicolon: end,
+	}
+}
+
+// Builtin type classes; programs cannot declare their own
+var typeClasses = map[string]bool{
+	"Eq":   true,
+	"Ord":  true,
+	"Show": true,
+}
+
+// checkConstrainedTypeVariables reports constrained type variables that do not appear in the return type or parameter
+// types of decl
+func (p *Parser) checkConstrainedTypeVariables(decl *ast.FunctionDeclaration) {
+	if decl.Context == nil {
+		return
+	}
+
+	used := make(map[string]bool)
+	signature := []ast.Type{decl.ReturnType}
+	for _, param := range decl.Parameters.Parameters {
+		signature = append(signature, param.Type)
+	}
+	for _, t := range signature {
+		ast.Inspect(t, func(n ast.Node) bool {
+			if tv, ok := n.(*ast.TypeVariable); ok {
+				used[tv.Name.Name] = true
+			}
+			return true
+		})
+	}
+
+	for _, constraint := range decl.Context.Constraints {
+		if class, ok := constraint.(*ast.NamedType); ok && len(class.Arguments) == 1 {
+			if tv, ok := unparenType(class.Arguments[0]).(*ast.TypeVariable); ok && !used[tv.Name.Name] {
+				p.error(tv.Pos(), "constrained type variable "+tv.Name.Name+" does not appear in the signature")
+			}
+		}
+	}
+}
+
+// continueTypeContext parses the constraints of a function type, which have been parsed as a type
+func (p *Parser) continueTypeContext(t ast.Type) *ast.TypeContext {
+	context := &ast.TypeContext{}
+
+	switch t := t.(type) {
+	case *ast.TupleType:
+		context.RoundBracketOpen = t.RoundBracketOpen
+		context.Constraints = t.Elements
+		context.RoundBracketClose = t.RoundBracketClose
+	case *ast.ParenthesizedType:
+		context.RoundBracketOpen = t.RoundBracketOpen
+		context.Constraints = []ast.Type{t.Type}
+		context.RoundBracketClose = t.RoundBracketClose
+	default:
+		context.Constraints = []ast.Type{t}
+	}
+
+	for _, constraint := range context.Constraints {
+		class, ok := constraint.(*ast.NamedType)
+		if !ok || len(class.Arguments) != 1 {
+			p.errorExpected(constraint.Pos(), "type class constraint")
+			continue
+		}
+		if !typeClasses[class.Name.Name] {
+			p.error(class.Name.Pos(), "unknown type class "+class.Name.Name)
+		}
+		if _, ok := unparenType(class.Arguments[0]).(*ast.TypeVariable); !ok {
+			p.errorExpected(class.Arguments[0].Pos(), "type variable")
+		}
+	}
+
+	context.DoubleArrow = p.expect(token.DOUBLE_ARROW)
+
+	return context
+}
+
+// parseTypeDeclaration parses a type synonym (type Pair t = (t, t);)
+func (p *Parser) parseTypeDeclaration() *ast.TypeDeclaration {
+	pos := p.expect(token.TYPE)
+	name := p.parseIdentifier()
+	if name.Name != "" && !isUpper(name.Name) {
+		// It would be a type variable wherever it is used
+		p.error(name.Pos(), "name of type synonym must start with an upper case letter")
+	}
+
+	var params []*ast.Identifier
+	for p.tok == token.IDENTIFIER {
+		params = append(params, p.parseIdentifier())
+	}
+
+	p.expect(token.IS)
+	t := p.parseTypeApplication()
+	end := p.expect(token.SEMICOLON)
+
+	return &ast.TypeDeclaration{
+		TypeKeyword:    pos,
+		Name:           name,
+		TypeParameters: params,
+		Type:           t,
+		Semicolon:      end,
+	}
+}
+
+func (p *Parser) parseDataDeclaration() *ast.DataDeclaration {
+	pos := p.expect(token.DATA)
+	name := p.parseIdentifier()
+
+	var params []*ast.Identifier
+	for p.tok == token.IDENTIFIER {
+		params = append(params, p.parseIdentifier())
+	}
+
+	p.expect(token.IS)
+
+	var constructors []*ast.DataConstructor
+	for {
+		constructors = append(constructors, p.parseDataConstructor())
+		if p.tok != token.BAR {
+			break
+		}
+		p.next()
+	}
+
+	end := p.expect(token.SEMICOLON)
+
+	return &ast.DataDeclaration{
+		Data:           pos,
+		Name:           name,
+		TypeParameters: params,
+		Constructors:   constructors,
+		Semicolon:      end,
+	}
+}
+
+func (p *Parser) parseDataConstructor() *ast.DataConstructor {
+	name := p.parseIdentifier()
+
+	var fields []ast.Type
+	for p.tok == token.IDENTIFIER || p.tok == token.ROUND_BRACKET_OPEN || p.tok == token.SQUARE_BRACKET_OPEN {
+		fields = append(fields, p.parseType())
+	}
+
+	return &ast.DataConstructor{
+		Name:   name,
+		Fields: fields,
+	}
+}
+
 func (p *Parser) parseType() ast.Type {
 	pos := p.pos
 
 	switch p.tok {
 	case token.IDENTIFIER:
-		name := p.parseIdentifier()
-
-		return &ast.NamedType{
-			Name: name,
-		}
+		return newTypeName(p.parseIdentifier())
 	case token.ROUND_BRACKET_OPEN:
 		p.next()
-		left := p.parseType()
-		p.expect(token.COMMA)
-		right := p.parseType()
+
+		var types []ast.Type
+		if p.tok != token.ARROW {
+			types = append(types, p.parseTypeApplication())
+			for p.tok == token.COMMA {
+				p.next()
+				types = append(types, p.parseTypeApplication())
+			}
+		}
+
+		if p.tok == token.ARROW {
+			// Function type
+			arrow := p.pos
+			p.next()
+			result := p.parseTypeApplication()
+			end := p.expect(token.ROUND_BRACKET_CLOSE)
+
+			return &ast.FunctionType{
+				RoundBracketOpen:  pos,
+				Parameters:        types,
+				Arrow:             arrow,
+				Result:            result,
+				RoundBracketClose: end,
+			}
+		}
+
 		end := p.expect(token.ROUND_BRACKET_CLOSE)
 
+		if len(types) == 1 {
+			return &ast.ParenthesizedType{
+				RoundBracketOpen:  pos,
+				Type:              types[0],
+				RoundBracketClose: end,
+			}
+		}
+
 		return &ast.TupleType{
 			RoundBracketOpen:  pos,
-			Left:              left,
-			Right:             right,
+			Elements:          types,
 			RoundBracketClose: end,
 		}
 	case token.SQUARE_BRACKET_OPEN:
 		p.next()
-		el := p.parseType()
+		el := p.parseTypeApplication()
 		end := p.expect(token.SQUARE_BRACKET_CLOSE)
 
 		return &ast.ListType{
@@ -157,6 +709,40 @@ func (p *Parser) parseType() ast.Type {
 	}
 }
 
+// parseTypeApplication parses a type that may apply a named type to type arguments (Tree t).  Type applications are only allowed
+// between brackets, because elsewhere the argument list cannot be told apart from the name that follows the type.
+func (p *Parser) parseTypeApplication() ast.Type {
+	t := p.parseType()
+
+	if named, ok := t.(*ast.NamedType); ok {
+		for p.tok == token.IDENTIFIER || p.tok == token.ROUND_BRACKET_OPEN || p.tok == token.SQUARE_BRACKET_OPEN {
+			named.Arguments = append(named.Arguments, p.parseType())
+		}
+	}
+
+	return t
+}
+
+// newTypeName returns a builtin type, a type variable if name starts with a lower case letter, or a named type
+func newTypeName(name *ast.Identifier) ast.Type {
+	switch name.Name {
+	case "Int", "Bool", "Char", "Void":
+		return &ast.BasicType{
+			Name: name,
+		}
+	}
+
+	if ch, _ := utf8.DecodeRuneInString(name.Name); unicode.IsLower(ch) {
+		return &ast.TypeVariable{
+			Name: name,
+		}
+	}
+
+	return &ast.NamedType{
+		Name: name,
+	}
+}
+
 func (p *Parser) parseIdentifier() *ast.Identifier {
 	pos := p.pos
 
@@ -172,73 +758,204 @@ func (p *Parser) parseIdentifier() *ast.Identifier {
 		NamePos: pos,
 		Name:    name,
 	}
-}
+}
+
+func (p *Parser) continueVariableDeclaration(t ast.Type, name *ast.Identifier) *ast.VariableDeclaration {
+	p.expect(token.IS)
+	initializer := p.parseExpression()
+	end := p.expect(token.SEMICOLON)
+
+	return &ast.VariableDeclaration{
+		Type:        t,
+		Name:        name,
+		Initializer: initializer,
+		Semicolon:   end,
+	}
+}
+
+// parseVarDeclaration parses a variable declaration with an inferred type, which may destructure its initializer
+func (p *Parser) parseVarDeclaration() *ast.VariableDeclaration {
+	pos := p.expect(token.VAR)
+
+	decl := &ast.VariableDeclaration{
+		Var: pos,
+	}
+
+	pattern := p.parseExpression()
+	if ident, ok := pattern.(*ast.Identifier); ok {
+		decl.Name = ident
+	} else {
+		p.checkAssignable(pattern, false)
+		decl.Pattern = pattern
+	}
+
+	p.expect(token.IS)
+	decl.Initializer = p.parseExpression()
+	decl.Semicolon = p.expect(token.SEMICOLON)
+
+	return decl
+}
+
+func (p *Parser) parseExpression() ast.Expression {
+	return p.parseExpressionWithMinPrecedence(token.MinPrecedence)
+}
+
+// parseNestedExpression parses an expression that is enclosed in brackets, in which "|" is a bitwise or and "<-" is "<" followed
+// by unary minus, even if the brackets are inside the first element of a list expression or a comprehension qualifier
+func (p *Parser) parseNestedExpression() ast.Expression {
+	outerBar, outerLeftArrow := p.barEndsExpression, p.leftArrowEndsExpression
+	p.barEndsExpression, p.leftArrowEndsExpression = false, false
+	expr := p.parseExpression()
+	p.barEndsExpression, p.leftArrowEndsExpression = outerBar, outerLeftArrow
+
+	return expr
+}
+
+func (p *Parser) parseExpressionWithMinPrecedence(minPrec token.Precedence) ast.Expression {
+	// Parse initial leg of expression
+	return p.continueBinaryExpression(p.parseUnaryExpression(), minPrec)
+}
+
+func (p *Parser) continueBinaryExpression(expr ast.Expression, minPrec token.Precedence) ast.Expression {
+	// Fixity of the previous binary operator in this precedence group
+	var prev token.Fixity
+	hasPrev := false
+
+	// If the next token is a binary operator, expr will become the lhs of that binary expression unless its operator precedence is
+	// lower than the current minPrec.
+	for {
+		tok, lit := p.tok, p.lit
+		switch {
+		case tok == token.INCREMENT:
+			// ++ is still scanned as a token.INCREMENT after it has been declared as a user-defined operator
+			tok, lit = token.OPERATOR, token.INCREMENT.Print()
+		case tok == token.DECREMENT:
+			// Binary minus followed by unary minus (a--1)
+			tok = token.MINUS
+		case tok == token.LEFT_ARROW && !p.leftArrowEndsExpression:
+			// Less than followed by unary minus (a<-1)
+			tok = token.LESS_THAN
+		}
+		if tok == token.BAR && p.barEndsExpression {
+			// Start of the qualifiers of a list comprehension
+			break
+		}
+
+		fixity, ok := p.operators.Binary(tok, lit)
+		if !ok {
+			// expr is not part of a binary expression
+			break
+		}
+		if fixity.Precedence < minPrec {
+			// Operator precedence is too low for this precedence group.  This expr will become the lhs of the next binary
+			// expression in an enclosing call to parseExpressionWithMinPrecedence().
+			break
+		}
+		if hasPrev && prev.Precedence == fixity.Precedence &&
+			(prev.Associativity == token.NonAssociative || fixity.Associativity == token.NonAssociative) {
+			p.error(p.pos, "non-associative operators of the same precedence cannot be chained")
+		}
+
+		op, symbol := tok, ""
+		if op == token.OPERATOR {
+			symbol = lit
+		}
+		if p.tok == token.DECREMENT || p.tok == token.LEFT_ARROW {
+			p.splitMinus()
+		} else {
+			p.next()
+		}
+
+		newMinPrec := fixity.Precedence
+		if fixity.Associativity != token.RightAssociative {
+			// Even if the next binary expression has the same precedence as the current one, it should not be parsed into the
+			// rhs of this expression because of left associativity.
+			// Instead, this expr will become the lhs of the next binary expression in the next iteration of this loop (or in an
+			// enclosing call to parseExpressionWithMinPrecedence()).
+			newMinPrec += 1
+		}
+		rhs := p.parseExpressionWithMinPrecedence(newMinPrec)
+
+		expr = &ast.BinaryExpression{
+			Left:     expr,
+			Operator: op,
+			Symbol:   symbol,
+			Right:    rhs,
+		}
+		prev, hasPrev = fixity, true
+	}
+
+	return expr
+}
+
+func (p *Parser) parseUnaryExpression() ast.Expression {
+	pos := p.pos
+
+	if p.tok == token.DECREMENT {
+		// Two unary minus operators (--1)
+		fixity, _ := p.operators.Unary(token.MINUS)
+		p.splitMinus()
+		operand := p.parseExpressionWithMinPrecedence(fixity.Precedence)
+
+		return &ast.UnaryExpression{
+			OperatorPos: pos,
+			Operator:    token.MINUS,
+			Operand:     operand,
+		}
+	}
+
+	if fixity, ok := p.operators.Unary(p.tok); ok {
+		minPrec := fixity.Precedence
+		if fixity.Associativity == token.LeftAssociative {
+			minPrec += 1
+		}
+
+		op := p.tok
+		p.next()
+
+		operand := p.parseExpressionWithMinPrecedence(minPrec)
+
+		return &ast.UnaryExpression{
+			OperatorPos: pos,
+			Operator:    op,
+			Operand:     operand,
+		}
+	}
 
-func (p *Parser) continueVariableDeclaration(t ast.Type, name *ast.Identifier) *ast.VariableDeclaration {
-	p.expect(token.IS)
-	initializer := p.parseExpression()
-	end := p.expect(token.SEMICOLON)
+	switch p.tok {
+	case token.BACKSLASH:
+		return p.parseLambdaExpression()
 
-	return &ast.VariableDeclaration{
-		Type:        t,
-		Name:        name,
-		Initializer: initializer,
-		Semicolon:   end,
+	default:
+		return p.parsePrimaryExpression()
 	}
 }
 
-func (p *Parser) parseExpression() ast.Expression {
-	return p.parseExpressionWithMinPrecedence(0)
+// splitMinus replaces the current token.DECREMENT or token.LEFT_ARROW by the minus it ends with, after its first character
+// has been consumed
+func (p *Parser) splitMinus() {
+	p.pos, p.tok = p.pos+1, token.MINUS
 }
 
-func (p *Parser) parseExpressionWithMinPrecedence(minPrec Precedence) ast.Expression {
-	// Parse initial leg of expression
-	expr := p.parseUnaryExpression()
+func (p *Parser) parsePrimaryExpression() ast.Expression {
+	return p.continuePrimaryExpression(p.parseOperand())
+}
 
-	// If the next token is a binary operator, expr will become the lhs of that binary expression unless its operator precedence is
-	// lower than the current minPrec.
-precedenceGroup:
-	for p.tok != token.EOF {
+func (p *Parser) continuePrimaryExpression(expr ast.Expression) ast.Expression {
+	// Field selectors and function calls bind tighter than any operator
+	for {
 		switch p.tok {
-		case token.PLUS, token.MINUS, token.MULTIPLY, token.DIVIDE, token.MODULO,
-			token.EQUALS, token.LESS_THAN, token.GREATER_THAN, token.LESS_THAN_EQUALS, token.GREATER_THAN_EQUALS, token.NOT_EQUALS,
-			token.AND, token.OR, token.COLON:
-			// Next token is a binary operator
-			prec, assoc := binaryPrecAssoc(p.tok)
-			if prec < minPrec {
-				// Operator precedence is too low for this precedence group.  This expr will become the lhs of the next binary
-				// expression in an enclosing call to parseExpressionWithMinPrecedence().
-				break precedenceGroup
-			}
-
-			op := p.tok
-			p.next()
-
-			newMinPrec := prec
-			if assoc == LeftAssociative {
-				// Even if the next binary expression has the same precedence as the current one, it should not be parsed into the
-				// rhs of this expression because of left associativity.
-				// Instead, this expr will become the lhs of the next binary expression in the next iteration of the precedenceGroup
-				// loop (or in an enclosing call to parseExpressionWithMinPrecedence()).
-				newMinPrec += 1
-			}
-			rhs := p.parseExpressionWithMinPrecedence(newMinPrec)
-
-			expr = &ast.BinaryExpression{
-				Left:     expr,
-				Operator: op,
-				Right:    rhs,
-			}
+		case token.PERIOD:
+			expr = p.continueFieldExpression(expr)
+		case token.ROUND_BRACKET_OPEN:
+			expr = p.continueFunctionCallExpression(expr)
 		default:
-			// expr is not part of a binary expression
-			break precedenceGroup
+			return expr
 		}
 	}
-
-	return expr
 }
 
-func (p *Parser) parseUnaryExpression() ast.Expression {
+func (p *Parser) parseOperand() ast.Expression {
 	pos := p.pos
 
 	switch p.tok {
@@ -248,28 +965,33 @@ func (p *Parser) parseUnaryExpression() ast.Expression {
 	case token.IDENTIFIER:
 		ident := p.parseIdentifier()
 
-		if p.tok == token.ROUND_BRACKET_OPEN {
-			// Function call
-			return p.continueFunctionCallExpression(ident)
+		if p.tok == token.CURLY_BRACKET_OPEN {
+			// Record constructor
+			return p.continueRecordExpression(ident)
 		}
 
 		// Identifier
 		return ident
 
+	case token.SQUARE_BRACKET_OPEN:
+		return p.parseListExpression()
+
 	case token.ROUND_BRACKET_OPEN:
 		p.next()
-		expr := p.parseExpression()
+		expr := p.parseNestedExpression()
 
 		if p.tok == token.COMMA {
 			// Tuple expression
-			p.next()
-			second := p.parseExpression()
+			elements := []ast.Expression{expr}
+			for p.tok == token.COMMA {
+				p.next()
+				elements = append(elements, p.parseNestedExpression())
+			}
 			end := p.expect(token.ROUND_BRACKET_CLOSE)
 
 			return &ast.TupleExpression{
 				RoundBracketOpen:  pos,
-				Left:              expr,
-				Right:             second,
+				Elements:          elements,
 				RoundBracketClose: end,
 			}
 		}
@@ -283,31 +1005,150 @@ func (p *Parser) parseUnaryExpression() ast.Expression {
 			RoundBracketClose: end,
 		}
 
-	case token.MINUS, token.NOT:
-		minPrec, assoc := unaryPrecAssoc(p.tok)
-		if assoc == LeftAssociative {
-			minPrec += 1
+	default:
+		p.errorExpected(p.pos, "unary expression")
+		p.next()
+
+		return &ast.BadExpression{
+			From: pos,
+			To:   p.pos,
 		}
+	}
+}
 
-		op := p.tok
+// parseListExpression parses a list literal ([1, 2, 3]), a range ([lo .. hi]) or a list comprehension ([x * 2 | x <- xs, x > 0])
+func (p *Parser) parseListExpression() ast.Expression {
+	pos := p.expect(token.SQUARE_BRACKET_OPEN)
+
+	if p.tok == token.SQUARE_BRACKET_CLOSE {
+		// Empty list with whitespace between the brackets
+		end := p.expect(token.SQUARE_BRACKET_CLOSE)
+
+		return &ast.ListExpression{
+			SquareBracketOpen:  pos,
+			SquareBracketClose: end,
+		}
+	}
+
+	// In [x | ...], "|" is the start of a list comprehension instead of a bitwise or
+	outer := p.barEndsExpression
+	p.barEndsExpression = true
+	first := p.parseExpression()
+	p.barEndsExpression = outer
+
+	switch p.tok {
+	case token.RANGE:
+		p.next()
+		high := p.parseNestedExpression()
+		end := p.expect(token.SQUARE_BRACKET_CLOSE)
+
+		return &ast.RangeExpression{
+			SquareBracketOpen:  pos,
+			Low:                first,
+			High:               high,
+			SquareBracketClose: end,
+		}
+
+	case token.BAR:
 		p.next()
 
-		operand := p.parseExpressionWithMinPrecedence(minPrec)
+		var qualifiers []ast.Node
+		for {
+			qualifiers = append(qualifiers, p.parseComprehensionQualifier())
+			if p.tok != token.COMMA {
+				break
+			}
+			p.next()
+		}
 
-		return &ast.UnaryExpression{
-			OperatorPos: pos,
-			Operator:    op,
-			Operand:     operand,
+		end := p.expect(token.SQUARE_BRACKET_CLOSE)
+
+		return &ast.ComprehensionExpression{
+			SquareBracketOpen:  pos,
+			Value:              first,
+			Qualifiers:         qualifiers,
+			SquareBracketClose: end,
 		}
+	}
 
-	default:
-		p.errorExpected(p.pos, "unary expression")
+	elements := []ast.Expression{first}
+	for p.tok == token.COMMA {
 		p.next()
+		elements = append(elements, p.parseNestedExpression())
+	}
 
-		return &ast.BadExpression{
-			From: pos,
-			To:   p.pos,
+	end := p.expect(token.SQUARE_BRACKET_CLOSE)
+
+	return &ast.ListExpression{
+		SquareBracketOpen:  pos,
+		Elements:           elements,
+		SquareBracketClose: end,
+	}
+}
+
+// parseComprehensionQualifier parses a generator (x <- xs) or a guard (x > 0)
+func (p *Parser) parseComprehensionQualifier() ast.Node {
+	outerBar, outerLeftArrow := p.barEndsExpression, p.leftArrowEndsExpression
+	p.barEndsExpression, p.leftArrowEndsExpression = false, true
+	expr := p.parseExpression()
+	p.barEndsExpression, p.leftArrowEndsExpression = outerBar, outerLeftArrow
+
+	if p.tok != token.LEFT_ARROW {
+		// Guard
+		return expr
+	}
+
+	p.checkPattern(expr)
+	arrow := p.pos
+	p.next()
+	list := p.parseNestedExpression()
+
+	return &ast.ComprehensionGenerator{
+		Pattern:   expr,
+		LeftArrow: arrow,
+		List:      list,
+	}
+}
+
+func (p *Parser) parseLambdaExpression() *ast.LambdaExpression {
+	pos := p.expect(token.BACKSLASH)
+
+	var params []*ast.Identifier
+	for p.tok == token.IDENTIFIER {
+		params = append(params, p.parseIdentifier())
+	}
+
+	arrow := p.expect(token.ARROW)
+
+	// The body extends as far to the right as possible
+	body := p.parseExpression()
+
+	return &ast.LambdaExpression{
+		Backslash:  pos,
+		Parameters: params,
+		Arrow:      arrow,
+		Body:       body,
+	}
+}
+
+func (p *Parser) continueFieldExpression(expr ast.Expression) *ast.FieldExpression {
+	p.expect(token.PERIOD)
+
+	var field *ast.Identifier
+	if p.tok == token.INTEGER {
+		// Positional access to a tuple element
+		field = &ast.Identifier{
+			NamePos: p.pos,
+			Name:    p.lit,
 		}
+		p.next()
+	} else {
+		field = p.parseIdentifier()
+	}
+
+	return &ast.FieldExpression{
+		Expression: expr,
+		Field:      field,
 	}
 }
 
@@ -336,7 +1177,7 @@ func (p *Parser) parseLiteralExpression() *ast.LiteralExpression {
 	}
 }
 
-func (p *Parser) continueFunctionCallExpression(name *ast.Identifier) *ast.FunctionCallExpression {
+func (p *Parser) continueFunctionCallExpression(function ast.Expression) *ast.FunctionCallExpression {
 	p.expect(token.ROUND_BRACKET_OPEN)
 
 	var args []ast.Expression
@@ -344,7 +1185,7 @@ func (p *Parser) continueFunctionCallExpression(name *ast.Identifier) *ast.Funct
 	if p.tok != token.ROUND_BRACKET_CLOSE {
 	arguments:
 		for {
-			args = append(args, p.parseExpression())
+			args = append(args, p.parseNestedExpression())
 
 			switch p.tok {
 			case token.COMMA:
@@ -362,12 +1203,45 @@ func (p *Parser) continueFunctionCallExpression(name *ast.Identifier) *ast.Funct
 	end := p.expect(token.ROUND_BRACKET_CLOSE)
 
 	return &ast.FunctionCallExpression{
-		Name:              name,
+		Function:          function,
 		Arguments:         args,
 		RoundBracketClose: end,
 	}
 }
 
+func (p *Parser) continueRecordExpression(name *ast.Identifier) *ast.RecordExpression {
+	open := p.expect(token.CURLY_BRACKET_OPEN)
+
+	var values []ast.Expression
+
+	if p.tok != token.CURLY_BRACKET_CLOSE {
+	values:
+		for {
+			values = append(values, p.parseNestedExpression())
+
+			switch p.tok {
+			case token.COMMA:
+				p.next()
+			case token.CURLY_BRACKET_CLOSE:
+				break values
+			default:
+				p.errorExpected(p.pos, token.COMMA.String()+" or "+token.CURLY_BRACKET_CLOSE.String())
+				p.next()
+				break values
+			}
+		}
+	}
+
+	end := p.expect(token.CURLY_BRACKET_CLOSE)
+
+	return &ast.RecordExpression{
+		Name:              name,
+		CurlyBracketOpen:  open,
+		Values:            values,
+		CurlyBracketClose: end,
+	}
+}
+
 func (p *Parser) continueFunctionDeclaration(returnType ast.Type, name *ast.Identifier) *ast.FunctionDeclaration {
 	params := p.parseFunctionParameters()
 
@@ -460,10 +1334,13 @@ func (p *Parser) parseVariableDeclarationOrStatement(allowVariableDeclaration bo
 
 		// Possible statements
 		switch p.tok {
-		case token.IS:
-			return nil, p.continueAssignmentStatement(ident)
-		case token.ROUND_BRACKET_OPEN:
-			return nil, p.continueFunctionCallStatement(ident)
+		case token.IS, token.PLUS_IS, token.MINUS_IS, token.MULTIPLY_IS, token.DIVIDE_IS, token.MODULO_IS,
+			token.INCREMENT, token.DECREMENT, token.PERIOD, token.ROUND_BRACKET_OPEN:
+			return nil, p.continueSimpleStatement(ident)
+		}
+		if _, ok := p.operators.Binary(p.tok, p.lit); ok {
+			// Destructuring assignment (x : xs = list;)
+			return nil, p.continueSimpleStatement(ident)
 		}
 
 		if !allowVariableDeclaration {
@@ -473,22 +1350,42 @@ func (p *Parser) parseVariableDeclarationOrStatement(allowVariableDeclaration bo
 		}
 
 		// Variable declaration with type ident
-		t := &ast.NamedType{
-			Name: ident,
-		}
+		t := newTypeName(ident)
 		name := p.parseIdentifier()
 		return p.continueVariableDeclaration(t, name), nil
 	case token.ROUND_BRACKET_OPEN, token.SQUARE_BRACKET_OPEN:
+		if allowVariableDeclaration && p.identifierAfterBrackets() {
+			// Variable declaration with a type in brackets ((Int, Bool) x = ...;)
+			t := p.parseType()
+			name := p.parseIdentifier()
+			return p.continueVariableDeclaration(t, name), nil
+		}
+
+		if p.tok == token.SQUARE_BRACKET_OPEN {
+			p.errorExpected(p.pos, "statement")
+			p.next()
+			return nil, &ast.BadStatement{}
+		}
+
+		// Destructuring assignment ((a, b) = f(x);) or call of a parenthesized expression ((\x -> print(x))(1);)
+		return nil, p.continueSimpleStatement(p.parseOperand())
+	case token.VAR:
 		if !allowVariableDeclaration {
 			p.errorExpected(p.pos, "statement")
 			p.next()
 			return nil, &ast.BadStatement{}
 		}
-		t := p.parseType()
-		name := p.parseIdentifier()
-		return p.continueVariableDeclaration(t, name), nil
+		return p.parseVarDeclaration(), nil
 	case token.WHILE:
 		return nil, p.parseWhileStatement()
+	case token.FOR:
+		return nil, p.parseForStatement()
+	case token.MATCH:
+		return nil, p.parseMatchStatement()
+	case token.BREAK:
+		return nil, p.parseBreakStatement()
+	case token.CONTINUE:
+		return nil, p.parseContinueStatement()
 	default:
 		if allowVariableDeclaration {
 			p.errorExpected(p.pos, "variable declaration or statement")
@@ -500,6 +1397,29 @@ func (p *Parser) parseVariableDeclarationOrStatement(allowVariableDeclaration bo
 	}
 }
 
+// identifierAfterBrackets reports whether the current opening bracket and its matching closing bracket are followed by an
+// identifier, like the type of a variable declaration ((Int, Bool) x) and unlike a destructuring assignment ((a, b) = ...)
+func (p *Parser) identifierAfterBrackets() bool {
+	s := p.scanner.Lookahead()
+	for depth := 1; depth > 0; {
+		_, tok, _ := s.Scan()
+		switch tok {
+		case token.ROUND_BRACKET_OPEN, token.SQUARE_BRACKET_OPEN:
+			depth++
+		case token.ROUND_BRACKET_CLOSE, token.SQUARE_BRACKET_CLOSE:
+			depth--
+		case token.EOF:
+			return false
+		}
+	}
+
+	_, tok, _ := s.Scan()
+	for tok == token.COMMENT {
+		_, tok, _ = s.Scan()
+	}
+	return tok == token.IDENTIFIER
+}
+
 func (p *Parser) parseStatement() ast.Statement {
 	_, stmt := p.parseVariableDeclarationOrStatement(false)
 	return stmt
@@ -556,7 +1476,7 @@ func (p *Parser) parseWhileStatement() *ast.WhileStatement {
 
 	p.expect(token.ROUND_BRACKET_CLOSE)
 
-	body := p.parseStatement()
+	body := p.parseLoopBody()
 
 	return &ast.WhileStatement{
 		While:     pos,
@@ -565,6 +1485,155 @@ func (p *Parser) parseWhileStatement() *ast.WhileStatement {
 	}
 }
 
+func (p *Parser) parseForStatement() *ast.ForStatement {
+	pos := p.expect(token.FOR)
+	p.expect(token.ROUND_BRACKET_OPEN)
+
+	variable := p.parseIdentifier()
+	p.expect(token.IN)
+	value := p.parseExpression()
+
+	var high ast.Expression
+	if p.tok == token.RANGE {
+		// Integer range
+		p.next()
+		high = p.parseExpression()
+	}
+
+	p.expect(token.ROUND_BRACKET_CLOSE)
+
+	body := p.parseLoopBody()
+
+	return &ast.ForStatement{
+		For:      pos,
+		Variable: variable,
+		Value:    value,
+		High:     high,
+		Body:     body,
+	}
+}
+
+func (p *Parser) parseMatchStatement() *ast.MatchStatement {
+	pos := p.expect(token.MATCH)
+	p.expect(token.ROUND_BRACKET_OPEN)
+
+	value := p.parseExpression()
+
+	p.expect(token.ROUND_BRACKET_CLOSE)
+	open := p.expect(token.CURLY_BRACKET_OPEN)
+
+	var cases []*ast.MatchCase
+	for p.tok != token.CURLY_BRACKET_CLOSE && p.tok != token.EOF {
+		cases = append(cases, p.parseMatchCase())
+	}
+
+	end := p.expect(token.CURLY_BRACKET_CLOSE)
+
+	return &ast.MatchStatement{
+		Match:             pos,
+		Value:             value,
+		CurlyBracketOpen:  open,
+		Cases:             cases,
+		CurlyBracketClose: end,
+	}
+}
+
+func (p *Parser) parseMatchCase() *ast.MatchCase {
+	pattern := p.parsePattern()
+	arrow := p.expect(token.ARROW)
+	body := p.parseStatement()
+
+	return &ast.MatchCase{
+		Pattern: pattern,
+		Arrow:   arrow,
+		Body:    body,
+	}
+}
+
+// parsePattern parses an expression and checks that it is a valid pattern.
+func (p *Parser) parsePattern() ast.Expression {
+	pattern := p.parseExpression()
+	p.checkPattern(pattern)
+
+	return pattern
+}
+
+func (p *Parser) checkPattern(pattern ast.Expression) {
+	switch e := pattern.(type) {
+	case *ast.Identifier, *ast.LiteralExpression, *ast.BadExpression:
+		// Variable, constructor without fields, or literal
+		return
+	case *ast.ListExpression:
+		// Empty list with whitespace between the brackets
+		if len(e.Elements) == 0 {
+			return
+		}
+	case *ast.UnaryExpression:
+		// Negative integer
+		if lit, ok := e.Operand.(*ast.LiteralExpression); ok && e.Operator == token.MINUS && lit.Kind == token.INTEGER {
+			return
+		}
+	case *ast.FunctionCallExpression:
+		// Constructor with fields
+		if ident, ok := e.Function.(*ast.Identifier); ok && isUpper(ident.Name) {
+			for _, arg := range e.Arguments {
+				p.checkPattern(arg)
+			}
+			return
+		}
+	case *ast.BinaryExpression:
+		if e.Operator == token.COLON {
+			p.checkPattern(e.Left)
+			p.checkPattern(e.Right)
+			return
+		}
+	case *ast.TupleExpression:
+		for _, el := range e.Elements {
+			p.checkPattern(el)
+		}
+		return
+	case *ast.ParenthesizedExpression:
+		p.checkPattern(e.Expression)
+		return
+	}
+
+	p.errorExpected(pattern.Pos(), "pattern")
+}
+
+func (p *Parser) parseLoopBody() ast.Statement {
+	p.loopDepth++
+	body := p.parseStatement()
+	p.loopDepth--
+
+	return body
+}
+
+func (p *Parser) parseBreakStatement() *ast.BreakStatement {
+	pos := p.expect(token.BREAK)
+	if p.loopDepth == 0 {
+		p.error(pos, "break is not in a loop")
+	}
+	end := p.expect(token.SEMICOLON)
+
+	return &ast.BreakStatement{
+		Break:     pos,
+		Semicolon: end,
+	}
+}
+
+func (p *Parser) parseContinueStatement() *ast.ContinueStatement {
+	pos := p.expect(token.CONTINUE)
+	if p.loopDepth == 0 {
+		p.error(pos, "continue is not in a loop")
+	}
+	end := p.expect(token.SEMICOLON)
+
+	return &ast.ContinueStatement{
+		Continue:  pos,
+		Semicolon: end,
+	}
+}
+
 func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 	pos := p.expect(token.CURLY_BRACKET_OPEN)
 
@@ -582,21 +1651,118 @@ func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 	}
 }
 
-func (p *Parser) continueAssignmentStatement(name *ast.Identifier) *ast.AssignmentStatement {
-	p.expect(token.IS)
+// continueSimpleStatement parses an assignment, increment or function call statement that starts with operand
+func (p *Parser) continueSimpleStatement(operand ast.Expression) ast.Statement {
+	expr := p.continuePrimaryExpression(operand)
+
+	switch p.tok {
+	case token.INCREMENT, token.DECREMENT:
+		return p.continueIncrementStatement(expr)
+	}
+
+	if call, ok := expr.(*ast.FunctionCallExpression); ok && !isAssignmentOperator(p.tok) {
+		return p.continueFunctionCallStatement(call)
+	}
+
+	expr = p.continueBinaryExpression(expr, token.MinPrecedence)
+	if p.tok == token.IS || !isAssignmentOperator(p.tok) {
+		// The target of a compound assignment is checked by continueAssignmentStatement
+		p.checkAssignable(expr, true)
+	}
+	return p.continueAssignmentStatement(expr)
+}
+
+// checkAssignable checks that target is a variable, a field of a variable (if fields is set), or a tuple or cons pattern of
+// those
+func (p *Parser) checkAssignable(target ast.Expression, fields bool) {
+	switch e := target.(type) {
+	case *ast.Identifier, *ast.BadExpression:
+		return
+	case *ast.FieldExpression:
+		if fields && isVariable(e.Expression) {
+			return
+		}
+	case *ast.BinaryExpression:
+		if e.Operator == token.COLON {
+			p.checkAssignable(e.Left, fields)
+			p.checkAssignable(e.Right, fields)
+			return
+		}
+	case *ast.TupleExpression:
+		for _, el := range e.Elements {
+			p.checkAssignable(el, fields)
+		}
+		return
+	case *ast.ParenthesizedExpression:
+		p.checkAssignable(e.Expression, fields)
+		return
+	}
+
+	if fields {
+		p.errorExpected(target.Pos(), "variable or field")
+	} else {
+		p.errorExpected(target.Pos(), "variable")
+	}
+}
+
+// isVariable reports whether expr is a variable or a (nested) field of a variable
+func isVariable(expr ast.Expression) bool {
+	switch e := expr.(type) {
+	case *ast.Identifier, *ast.BadExpression:
+		return true
+	case *ast.FieldExpression:
+		return isVariable(e.Expression)
+	}
+	return false
+}
+
+func (p *Parser) continueAssignmentStatement(target ast.Expression) *ast.AssignmentStatement {
+	op := token.IS
+	if isAssignmentOperator(p.tok) {
+		op = p.tok
+		if op != token.IS && !isVariable(target) {
+			p.errorExpected(target.Pos(), "variable or field")
+		}
+		p.next()
+	} else {
+		p.expect(token.IS)
+	}
 	value := p.parseExpression()
 	end := p.expect(token.SEMICOLON)
 
 	return &ast.AssignmentStatement{
-		Name:      name,
+		Target:    target,
+		Operator:  op,
 		Value:     value,
 		Semicolon: end,
 	}
 }
 
-func (p *Parser) continueFunctionCallStatement(name *ast.Identifier) *ast.FunctionCallStatement {
-	call := p.continueFunctionCallExpression(name)
+func isAssignmentOperator(tok token.Token) bool {
+	switch tok {
+	case token.IS, token.PLUS_IS, token.MINUS_IS, token.MULTIPLY_IS, token.DIVIDE_IS, token.MODULO_IS:
+		return true
+	}
+	return false
+}
+
+func (p *Parser) continueIncrementStatement(target ast.Expression) *ast.IncrementStatement {
+	if !isVariable(target) {
+		p.errorExpected(target.Pos(), "variable or field")
+	}
+	pos, op := p.pos, p.tok
+	p.next()
+	end := p.expect(token.SEMICOLON)
+
+	return &ast.IncrementStatement{
+		Target:      target,
+		OperatorPos: pos,
+		Operator:    op,
+		Semicolon:   end,
+	}
+}
 
+func (p *Parser) continueFunctionCallStatement(call *ast.FunctionCallExpression) *ast.FunctionCallStatement {
 	end := p.expect(token.SEMICOLON)
 
 	return &ast.FunctionCallStatement{
@@ -1,11 +1,15 @@
 package parser
 
 import (
+	"bytes"
+	"fmt"
 	"io/ioutil"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/Minnozz/gospl/ast"
+	"github.com/Minnozz/gospl/scanner"
 	"github.com/Minnozz/gospl/token"
 )
 
@@ -79,3 +83,448 @@ func parseTestFile(t *testing.T, name string) {
 		}
 	}
 }
+
+func TestTypeSynonymCycles(t *testing.T) {
+	tests := []struct {
+		src    string
+		errors []string
+	}{
+		{
+			src: `
+type Pair t = (t, t);
+type A = (Int, [B]);
+type B = Pair C;
+type C = A;
+type List = [List];
+type Forest t = [Tree t];
+data Tree t = Node t (Forest t);
+`,
+			errors: []string{
+				"test.spl:3:6: invalid recursive type synonym: A -> B -> C -> A",
+				"test.spl:6:6: invalid recursive type synonym: List -> List",
+			},
+		},
+		{
+			// A lower case name would be a type variable where it is used, so its cycle could not be found
+			src: "type a = [a];\ntype pair t = (t, t);\n",
+			errors: []string{
+				"test.spl:1:6: name of type synonym must start with an upper case letter",
+				"test.spl:2:6: name of type synonym must start with an upper case letter",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		checkErrors(t, test.src, test.errors)
+	}
+}
+
+func TestVoidOnlyAsReturnType(t *testing.T) {
+	src := `
+Void f((Int -> Void) g) { g(1); }
+[Void] xs = [];
+Void h(Void x) { }
+record R { (Void, Int) field; }
+data D = D Void;
+type T = (Void -> Void);
+(Void) j() { }
+((Void)) k(((Int) -> (Void)) f) { }
+[(Void)] l() { }
+`
+	checkErrors(t, src, []string{
+		"test.spl:3:2: Void can only be used as a return type",
+		"test.spl:4:8: Void can only be used as a return type",
+		"test.spl:5:13: Void can only be used as a return type",
+		"test.spl:6:12: Void can only be used as a return type",
+		"test.spl:7:11: Void can only be used as a return type",
+		"test.spl:10:3: Void can only be used as a return type",
+	})
+}
+
+func TestTypeNames(t *testing.T) {
+	fileInfo := &token.FileInfo{
+		Filename: "test.spl",
+	}
+
+	p := &Parser{}
+	p.Init(fileInfo, []byte("(Tree Int t) x = y;"))
+	file := p.Parse()
+
+	named, ok := file.Declarations[0].(*ast.VariableDeclaration).Type.(*ast.ParenthesizedType).Type.(*ast.NamedType)
+	if !ok || len(named.Arguments) != 2 {
+		t.Fatalf("Expected a named type with two arguments")
+	}
+	if _, ok := named.Arguments[0].(*ast.BasicType); !ok {
+		t.Errorf("Expected Int to be a basic type, got %T", named.Arguments[0])
+	}
+	if _, ok := named.Arguments[1].(*ast.TypeVariable); !ok {
+		t.Errorf("Expected t to be a type variable, got %T", named.Arguments[1])
+	}
+}
+
+type parserTest struct {
+	src    string   // Body of a function, which starts on line 2
+	shape  string   // Shapes of the variable declarations and statements in the body, separated by "; "; not checked if empty
+	errors []string // Expected errors
+}
+
+func runParserTests(t *testing.T, tests []parserTest) {
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			decl, errors := parseTestBody(test.src)
+
+			if len(errors) != len(test.errors) {
+				t.Errorf("Expected %d errors, got %v", len(test.errors), errors)
+			} else {
+				for i, err := range errors {
+					if err.Error() != test.errors[i] {
+						t.Errorf("Expected error %q, got %q", test.errors[i], err.Error())
+					}
+				}
+			}
+
+			if test.shape == "" {
+				return
+			}
+			var shapes []string
+			for _, varDecl := range decl.Variables {
+				shapes = append(shapes, shape(varDecl))
+			}
+			for _, stmt := range decl.Statements {
+				shapes = append(shapes, shape(stmt))
+			}
+			if got := strings.Join(shapes, "; "); got != test.shape {
+				t.Errorf("Parsed %q as %s, expected %s", test.src, got, test.shape)
+			}
+		})
+	}
+}
+
+// parseTestBody parses src as the body of a function
+func parseTestBody(src string) (*ast.FunctionDeclaration, scanner.ErrorList) {
+	fileInfo := &token.FileInfo{
+		Filename: "test.spl",
+	}
+
+	p := &Parser{}
+	p.Init(fileInfo, []byte("Void main() {\n"+src+"\n}\n"))
+	file := p.Parse()
+
+	decl, ok := file.Declarations[len(file.Declarations)-1].(*ast.FunctionDeclaration)
+	if !ok {
+		return &ast.FunctionDeclaration{}, p.Errors
+	}
+	return decl, p.Errors
+}
+
+// shape describes the tree rooted at n by the types of its nodes, e.g. FieldExpression(xs, hd).  Identifiers and literals are
+// described by their name or value, and operators are described before the operands.
+func shape(n ast.Node) string {
+	var buf bytes.Buffer
+	var children []int // Number of children described so far, for each node that is being described
+
+	ast.Walk(n, ast.Inspector{
+		Pre: func(n ast.Node) bool {
+			if len(children) > 0 {
+				if children[len(children)-1] > 0 {
+					buf.WriteString(", ")
+				}
+				children[len(children)-1]++
+			}
+
+			switch n := n.(type) {
+			case *ast.Identifier:
+				buf.WriteString(n.Name)
+				return false
+			case *ast.LiteralExpression:
+				buf.WriteString(n.Value)
+				return false
+			}
+
+			buf.WriteString(strings.TrimPrefix(fmt.Sprintf("%T", n), "*ast.") + "(")
+			children = append(children, 0)
+
+			var op string
+			switch n := n.(type) {
+			case *ast.UnaryExpression:
+				op = n.Operator.Print()
+			case *ast.BinaryExpression:
+				op = n.Symbol
+				if n.Operator != token.OPERATOR {
+					op = n.Operator.Print()
+				}
+			case *ast.AssignmentStatement:
+				op = n.Operator.Print()
+			case *ast.IncrementStatement:
+				op = n.Operator.Print()
+			}
+			if op != "" {
+				buf.WriteString(op)
+				children[len(children)-1]++
+			}
+
+			return true
+		},
+		Post: func(n ast.Node) {
+			buf.WriteString(")")
+			children = children[:len(children)-1]
+		},
+	})
+
+	return buf.String()
+}
+
+var fieldTests = []parserTest{
+	{src: "x = xs.tl.hd;", shape: "AssignmentStatement(=, x, FieldExpression(FieldExpression(xs, tl), hd))"},
+	{src: "p.fst = 3;", shape: "AssignmentStatement(=, FieldExpression(p, fst), 3)"},
+	{src: "xs.tl.hd = p.snd.fst;", shape: "AssignmentStatement(=, FieldExpression(FieldExpression(xs, tl), hd), FieldExpression(FieldExpression(p, snd), fst))"},
+	{src: "x = f(y).hd + 1;", shape: "AssignmentStatement(=, x, BinaryExpression(+, FieldExpression(FunctionCallExpression(f, y), hd), 1))"},
+	{src: "f(y).hd = 1;", errors: []string{"test.spl:2:1: expected variable or field"}},
+	{src: "f(y) = 1;", errors: []string{"test.spl:2:1: expected variable or field"}},
+}
+
+func TestFieldSelectors(t *testing.T) {
+	runParserTests(t, fieldTests)
+}
+
+var forTests = []parserTest{
+	{src: "for (x in xs) print(x);", shape: "ForStatement(x, xs, FunctionCallStatement(FunctionCallExpression(print, x)))"},
+	{src: "for (i in 1 .. n + 1) { }", shape: "ForStatement(i, 1, BinaryExpression(+, n, 1), BlockStatement())"},
+	{src: "for (i in [1 .. n]) { }", shape: "ForStatement(i, RangeExpression(1, n), BlockStatement())"},
+}
+
+func TestForStatements(t *testing.T) {
+	runParserTests(t, forTests)
+}
+
+var loopControlTests = []parserTest{
+	{src: "break;", errors: []string{"test.spl:2:1: break is not in a loop"}},
+	{src: "continue;", errors: []string{"test.spl:2:1: continue is not in a loop"}},
+	{src: "while (True) { } break;", errors: []string{"test.spl:2:18: break is not in a loop"}},
+	{src: "for (x in xs) { } continue;", errors: []string{"test.spl:2:19: continue is not in a loop"}},
+	// The body of a lambda is an expression, so it cannot break out of a loop around the lambda
+	{src: "while (True) f = \\x -> break;", errors: []string{"test.spl:2:24: expected unary expression, got BREAK"}},
+	{
+		src:   "while (True) { if (x) break; else { match (y) { 0 -> continue; n -> { break; } } } }",
+		shape: "WhileStatement(True, BlockStatement(IfStatement(x, BreakStatement(), BlockStatement(MatchStatement(y, MatchCase(0, ContinueStatement()), MatchCase(n, BlockStatement(BreakStatement())))))))",
+	},
+	{src: "for (x in xs) { while (x) { } break; }", shape: "ForStatement(x, xs, BlockStatement(WhileStatement(x, BlockStatement()), BreakStatement()))"},
+}
+
+func TestLoopControl(t *testing.T) {
+	runParserTests(t, loopControlTests)
+}
+
+var matchTests = []parserTest{
+	{
+		src:   "match (t) { Leaf -> return 0; Node(l, x, Node(Leaf, y, r)) -> return x; }",
+		shape: "MatchStatement(t, MatchCase(Leaf, ReturnStatement(0)), MatchCase(FunctionCallExpression(Node, l, x, FunctionCallExpression(Node, Leaf, y, r)), ReturnStatement(x)))",
+	},
+	{
+		src:   "match (xs) { [] -> return 0; (a, -1) : ys -> return a; }",
+		shape: "MatchStatement(xs, MatchCase([], ReturnStatement(0)), MatchCase(BinaryExpression(:, TupleExpression(a, UnaryExpression(-, 1)), ys), ReturnStatement(a)))",
+	},
+	{
+		src:   "match (xs) { [ ] -> return 0; x : [ ] -> return x; }",
+		shape: "MatchStatement(xs, MatchCase(ListExpression(), ReturnStatement(0)), MatchCase(BinaryExpression(:, x, ListExpression()), ReturnStatement(x)))",
+	},
+	{src: "match (xs) { [x] -> return x; }", errors: []string{"test.spl:2:14: expected pattern"}},
+	{src: "match (x) { f(y) -> return y; }", errors: []string{"test.spl:2:13: expected pattern"}},
+	{src: "match (x) { Just(f(y)) -> return y; }", errors: []string{"test.spl:2:18: expected pattern"}},
+	{src: "match (x) { y + 1 -> return y; }", errors: []string{"test.spl:2:13: expected pattern"}},
+	{src: "match (x) { -y -> return y; }", errors: []string{"test.spl:2:13: expected pattern"}},
+}
+
+func TestMatchPatterns(t *testing.T) {
+	runParserTests(t, matchTests)
+}
+
+var functionCallTests = []parserTest{
+	{src: "print(1); (g)(1);", shape: "FunctionCallStatement(FunctionCallExpression(print, 1)); FunctionCallStatement(FunctionCallExpression(ParenthesizedExpression(g), 1))"},
+	{
+		src:   "print(1); (\\x -> print(x))(1);",
+		shape: "FunctionCallStatement(FunctionCallExpression(print, 1)); FunctionCallStatement(FunctionCallExpression(ParenthesizedExpression(LambdaExpression(x, FunctionCallExpression(print, x))), 1))",
+	},
+	{src: "print(1); f(1)(2).hd(3);", shape: "FunctionCallStatement(FunctionCallExpression(print, 1)); FunctionCallStatement(FunctionCallExpression(FieldExpression(FunctionCallExpression(FunctionCallExpression(f, 1), 2), hd), 3))"},
+	{src: "print(1); (g)(1) = 2;", errors: []string{"test.spl:2:11: expected variable or field"}},
+
+	// The body of a lambda extends as far to the right as possible
+	{src: "f = \\x y -> x + y * 2;", shape: "AssignmentStatement(=, f, LambdaExpression(x, y, BinaryExpression(+, x, BinaryExpression(*, y, 2))))"},
+	{src: "f = \\x -> \\y -> x : y;", shape: "AssignmentStatement(=, f, LambdaExpression(x, LambdaExpression(y, BinaryExpression(:, x, y))))"},
+	{src: "f = 1 + \\x -> x + 2;", shape: "AssignmentStatement(=, f, BinaryExpression(+, 1, LambdaExpression(x, BinaryExpression(+, x, 2))))"},
+	{src: "f = map(\\x -> x + 1, xs);", shape: "AssignmentStatement(=, f, FunctionCallExpression(map, LambdaExpression(x, BinaryExpression(+, x, 1)), xs))"},
+	{src: "f = (\\x -> x)(1) + 2;", shape: "AssignmentStatement(=, f, BinaryExpression(+, FunctionCallExpression(ParenthesizedExpression(LambdaExpression(x, x)), 1), 2))"},
+	{src: "f = \\ -> 1;", shape: "AssignmentStatement(=, f, LambdaExpression(1))"},
+}
+
+func TestFunctionCalls(t *testing.T) {
+	runParserTests(t, functionCallTests)
+}
+
+// checkErrors parses src as a file and checks that it has the expected errors
+func checkErrors(t *testing.T, src string, expected []string) {
+	checkModeErrors(t, 0, src, expected)
+}
+
+func checkModeErrors(t *testing.T, mode Mode, src string, expected []string) {
+	fileInfo := &token.FileInfo{
+		Filename: "test.spl",
+	}
+
+	p := &Parser{Mode: mode}
+	p.Init(fileInfo, []byte(src))
+	p.Parse()
+
+	if len(p.Errors) != len(expected) {
+		t.Fatalf("Expected %d errors, got %v", len(expected), p.Errors)
+	}
+	for i, err := range p.Errors {
+		if err.Error() != expected[i] {
+			t.Errorf("Expected error %q, got %q", expected[i], err.Error())
+		}
+	}
+}
+
+func TestImportsFirst(t *testing.T) {
+	src := `
+import "lists";
+/* Comments may appear before imports */
+import "maybe";
+Int x = 1;
+import "tuples";
+Void f() { }
+import "trees";
+`
+	checkErrors(t, src, []string{
+		"test.spl:6:1: imports must appear before other declarations",
+		"test.spl:8:1: imports must appear before other declarations",
+	})
+}
+
+var listTests = []parserTest{
+	{src: "xs = [1, 2 + 3, f(x)];", shape: "AssignmentStatement(=, xs, ListExpression(1, BinaryExpression(+, 2, 3), FunctionCallExpression(f, x)))"},
+	{src: "xs = [ ];", shape: "AssignmentStatement(=, xs, ListExpression())"},
+	{src: "xs = [lo .. hi - 1];", shape: "AssignmentStatement(=, xs, RangeExpression(lo, BinaryExpression(-, hi, 1)))"},
+	{
+		src:   "xs = [x * y | x <- xs, x > 0, (a, y) <- f(x), a];",
+		shape: "AssignmentStatement(=, xs, ComprehensionExpression(BinaryExpression(*, x, y), ComprehensionGenerator(x, xs), BinaryExpression(>, x, 0), ComprehensionGenerator(TupleExpression(a, y), FunctionCallExpression(f, x)), a))",
+	},
+	{src: "xs = [x | x<-xs, (x<-1)];", shape: "AssignmentStatement(=, xs, ComprehensionExpression(x, ComprehensionGenerator(x, xs), ParenthesizedExpression(BinaryExpression(<, x, UnaryExpression(-, 1)))))"},
+	{src: "xs = [x | x <- [y | y <- ys]];", shape: "AssignmentStatement(=, xs, ComprehensionExpression(x, ComprehensionGenerator(x, ComprehensionExpression(y, ComprehensionGenerator(y, ys)))))"},
+	{src: "xs = [x | f(x) <- xs];", errors: []string{"test.spl:2:11: expected pattern"}},
+
+	// "<-" is "<" followed by unary minus outside comprehension qualifiers
+	{src: "if (x<-1) { }", shape: "IfStatement(BinaryExpression(<, x, UnaryExpression(-, 1)), BlockStatement())"},
+	{src: "b = x<--1 && [x<-1] == [y];", shape: "AssignmentStatement(=, b, BinaryExpression(&&, BinaryExpression(<, x, UnaryExpression(-, UnaryExpression(-, 1))), BinaryExpression(==, ListExpression(BinaryExpression(<, x, UnaryExpression(-, 1))), ListExpression(y))))"},
+	{src: "xs = [x<-1 | x <- xs];", shape: "AssignmentStatement(=, xs, ComprehensionExpression(BinaryExpression(<, x, UnaryExpression(-, 1)), ComprehensionGenerator(x, xs)))"},
+}
+
+func TestLists(t *testing.T) {
+	runParserTests(t, listTests)
+}
+
+var tupleTests = []parserTest{
+	{src: "(Int, Bool, [t]) x = (1, True, []);", shape: "VariableDeclaration(TupleType(BasicType(Int), BasicType(Bool), ListType(TypeVariable(t))), x, TupleExpression(1, True, []))"},
+	{src: "x = (a, (b, c), d).1.0;", shape: "AssignmentStatement(=, x, FieldExpression(FieldExpression(TupleExpression(a, TupleExpression(b, c), d), 1), 0))"},
+	{src: "t.2 = t.0 + t.fst;", shape: "AssignmentStatement(=, FieldExpression(t, 2), BinaryExpression(+, FieldExpression(t, 0), FieldExpression(t, fst)))"},
+	{src: "x = (1);", shape: "AssignmentStatement(=, x, ParenthesizedExpression(1))"},
+}
+
+func TestTuples(t *testing.T) {
+	runParserTests(t, tupleTests)
+}
+
+var destructuringTests = []parserTest{
+	// Destructuring assignments can be the first statement, where variable declarations are still allowed
+	{src: "(x : xs, y) = z;", shape: "AssignmentStatement(=, TupleExpression(BinaryExpression(:, x, xs), y), z)"},
+	{src: "(a.fst, b) = z;", shape: "AssignmentStatement(=, TupleExpression(FieldExpression(a, fst), b), z)"},
+	{src: "(a, b) : rest = z;", shape: "AssignmentStatement(=, BinaryExpression(:, TupleExpression(a, b), rest), z)"},
+	{src: "(g)(1);", shape: "FunctionCallStatement(FunctionCallExpression(ParenthesizedExpression(g), 1))"},
+	{src: "print(1); (x : xs, y) = z;", shape: "FunctionCallStatement(FunctionCallExpression(print, 1)); AssignmentStatement(=, TupleExpression(BinaryExpression(:, x, xs), y), z)"},
+	{src: "print(1); (a.fst, b) = z;", shape: "FunctionCallStatement(FunctionCallExpression(print, 1)); AssignmentStatement(=, TupleExpression(FieldExpression(a, fst), b), z)"},
+
+	// Variable declarations with a type in brackets
+	{src: "(Int, (t -> [u])) p = z; [(Tree t)] ts = []; (a, b) = p;", shape: "VariableDeclaration(TupleType(BasicType(Int), FunctionType(TypeVariable(t), ListType(TypeVariable(u)))), p, z); VariableDeclaration(ListType(ParenthesizedType(NamedType(Tree, TypeVariable(t)))), ts, []); AssignmentStatement(=, TupleExpression(a, b), p)"},
+	{src: "var (q, r) : rest = z;", shape: "VariableDeclaration(BinaryExpression(:, TupleExpression(q, r), rest), z)"},
+
+	{src: "(a + b, c) = z;", errors: []string{"test.spl:2:2: expected variable or field"}},
+	{src: "(f(a), b) = z;", errors: []string{"test.spl:2:2: expected variable or field"}},
+	{src: "var (a.fst, b) = z;", errors: []string{"test.spl:2:6: expected variable"}},
+}
+
+func TestDestructuring(t *testing.T) {
+	runParserTests(t, destructuringTests)
+}
+
+var compoundAssignmentTests = []parserTest{
+	{
+		src:   "x += 1; xs.hd -= 2; y *= 3 + 4; y /= 5; y %= 6;",
+		shape: "AssignmentStatement(+=, x, 1); AssignmentStatement(-=, FieldExpression(xs, hd), 2); AssignmentStatement(*=, y, BinaryExpression(+, 3, 4)); AssignmentStatement(/=, y, 5); AssignmentStatement(%=, y, 6)",
+	},
+	{src: "x++; p.fst.hd--;", shape: "IncrementStatement(++, x); IncrementStatement(--, FieldExpression(FieldExpression(p, fst), hd))"},
+	{src: "x = y--1;", shape: "AssignmentStatement(=, x, BinaryExpression(-, y, UnaryExpression(-, 1)))"},
+	{src: "(a, b) += 1;", errors: []string{"test.spl:2:1: expected variable or field"}},
+	{src: "x : xs -= 1;", errors: []string{"test.spl:2:1: expected variable or field"}},
+	{src: "f(x) *= 1;", errors: []string{"test.spl:2:1: expected variable or field"}},
+	{src: "f(x)++;", errors: []string{"test.spl:2:1: expected variable or field"}},
+	{src: "(x)--;", errors: []string{"test.spl:2:1: expected variable or field"}},
+}
+
+func TestCompoundAssignments(t *testing.T) {
+	runParserTests(t, compoundAssignmentTests)
+}
+
+var bitwiseTests = []parserTest{
+	{src: "x = a&b && c&&d;", shape: "AssignmentStatement(=, x, BinaryExpression(&&, BinaryExpression(&&, BinaryExpression(&, a, b), c), d))"},
+	{src: "x = ~a ^ b << 2 >> c;", shape: "AssignmentStatement(=, x, BinaryExpression(^, UnaryExpression(~, a), BinaryExpression(>>, BinaryExpression(<<, b, 2), c)))"},
+	{src: "x = a|b || c;", shape: "AssignmentStatement(=, x, BinaryExpression(||, BinaryExpression(|, a, b), c))"},
+	{src: "x = 1 << -1;", shape: "AssignmentStatement(=, x, BinaryExpression(<<, 1, UnaryExpression(-, 1)))"},
+
+	// "|" starts the qualifiers of a list comprehension, unless it is in brackets or after the first element
+	{src: "xs = [a | b];", shape: "AssignmentStatement(=, xs, ComprehensionExpression(a, b))"},
+	{src: "xs = [(a | b)];", shape: "AssignmentStatement(=, xs, ListExpression(ParenthesizedExpression(BinaryExpression(|, a, b))))"},
+	{src: "xs = [a, b | c];", shape: "AssignmentStatement(=, xs, ListExpression(a, BinaryExpression(|, b, c)))"},
+	{src: "xs = [f(a | b) .. a | b];", shape: "AssignmentStatement(=, xs, RangeExpression(FunctionCallExpression(f, BinaryExpression(|, a, b)), BinaryExpression(|, a, b)))"},
+	{src: "xs = [a | a <- b | c, a | 1];", shape: "AssignmentStatement(=, xs, ComprehensionExpression(a, ComprehensionGenerator(a, BinaryExpression(|, b, c)), BinaryExpression(|, a, 1)))"},
+}
+
+func TestBitwiseOperators(t *testing.T) {
+	runParserTests(t, bitwiseTests)
+}
+
+func TestConstraintsOnlyOnFunctions(t *testing.T) {
+	src := `
+Eq t => t x = y;
+(Eq t, Ord u) => (t, u) p = q;
+Eq t => Bool eq(t a, t b) { return a == b; }
+(Eq t, Show t) => Void show(t a) { print(a); }
+`
+	checkErrors(t, src, []string{
+		"test.spl:2:1: constraints are only allowed on function declarations",
+		"test.spl:3:1: constraints are only allowed on function declarations",
+	})
+}
+
+func TestConstraintErrors(t *testing.T) {
+	tests := []struct {
+		src    string
+		errors []string
+	}{
+		{"Foo t => Int f(t x) { return 1; }\n", []string{
+			"test.spl:1:1: unknown type class Foo",
+		}},
+		{"Eq Int => Int f(Int x) { return x; }\n", []string{
+			"test.spl:1:4: expected type variable",
+		}},
+		{"Eq t => Int f(Int x) { return x; }\n", []string{
+			"test.spl:1:4: constrained type variable t does not appear in the signature",
+		}},
+		{"(Eq t, Ord u) => u f(t x) { return x; }\n", nil},
+		{"(Eq t, Show u) => Void f(t x) { print(x); }\n", []string{
+			"test.spl:1:13: constrained type variable u does not appear in the signature",
+		}},
+	}
+	for _, test := range tests {
+		checkErrors(t, test.src, test.errors)
+	}
+}
@@ -4,51 +4,32 @@ import (
 	"github.com/Minnozz/gospl/token"
 )
 
-type Precedence int
-
-// Precedence groups, from lowest to highest
-const (
-	binaryBoolean        Precedence = iota // &&, ||
-	binaryComparison                       // ==, !=, <, >, <=, >=
-	unaryNot                               // !
-	binaryColon                            // :
-	binaryAddition                         // +, -
-	binaryMultiplication                   // *, /, %
-	unaryMinus                             // -
-	highestPrecedence                      // default
-)
+// OperatorTable holds the fixity of the builtin operators and of the user-defined operators declared so far.
+type OperatorTable struct {
+	declared map[string]token.Fixity
+}
 
-type Associativity int
+func NewOperatorTable() *OperatorTable {
+	return &OperatorTable{
+		declared: make(map[string]token.Fixity),
+	}
+}
 
-const (
-	LeftAssociative Associativity = iota
-	RightAssociative
-)
+// Unary returns the fixity of a unary operator
+func (t *OperatorTable) Unary(op token.Token) (token.Fixity, bool) {
+	return token.UnaryFixity(op)
+}
 
-func unaryPrecAssoc(op token.Token) (Precedence, Associativity) {
-	switch op {
-	case token.NOT:
-		return unaryNot, RightAssociative
-	case token.MINUS:
-		return unaryMinus, RightAssociative
-	default:
-		panic("invalid unary operator: " + op.String())
+// Binary returns the fixity of a binary operator.  lit is the spelling of a user-defined operator (token.OPERATOR).
+func (t *OperatorTable) Binary(op token.Token, lit string) (token.Fixity, bool) {
+	if op == token.OPERATOR {
+		fixity, ok := t.declared[lit]
+		return fixity, ok
 	}
+	return token.BinaryFixity(op)
 }
 
-func binaryPrecAssoc(op token.Token) (Precedence, Associativity) {
-	switch op {
-	case token.PLUS, token.MINUS:
-		return binaryAddition, LeftAssociative
-	case token.MULTIPLY, token.DIVIDE, token.MODULO:
-		return binaryMultiplication, LeftAssociative
-	case token.EQUALS, token.LESS_THAN, token.GREATER_THAN, token.LESS_THAN_EQUALS, token.GREATER_THAN_EQUALS, token.NOT_EQUALS:
-		return binaryComparison, LeftAssociative
-	case token.AND, token.OR:
-		return binaryBoolean, LeftAssociative
-	case token.COLON:
-		return binaryColon, LeftAssociative
-	default:
-		panic("invalid binary operator: " + op.String())
-	}
+// Declare adds a user-defined binary operator
+func (t *OperatorTable) Declare(op string, fixity token.Fixity) {
+	t.declared[op] = fixity
 }
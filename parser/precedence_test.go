@@ -0,0 +1,164 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/Minnozz/gospl/ast"
+	"github.com/Minnozz/gospl/scanner"
+	"github.com/Minnozz/gospl/token"
+)
+
+const testFixityDeclarations = `
+infixr 5 ++ append;
+infixl 6 <+> add;
+infix 3 === equal;
+infixl 9 !? index;
+infixr 0 $ apply;
+`
+
+var precedenceTests = []struct {
+	src  string
+	tree string
+}{
+	// Builtin operators
+	{"1 + 2 * 3", "(1 + (2 * 3))"},
+	{"1 * 2 + 3", "((1 * 2) + 3)"},
+	{"1 - 2 - 3", "((1 - 2) - 3)"},
+	{"40 / 4 / 2", "((40 / 4) / 2)"},
+	{"1 % 2 * 3", "((1 % 2) * 3)"},
+	{"a && b || c", "((a && b) || c)"},
+	{"a || b && c", "((a || b) && c)"},
+	{"a == b && c < d", "((a == b) && (c < d))"},
+	{"a <= b == c", "((a <= b) == c)"},
+	{"1 : 2 : []", "(1 : (2 : []))"},
+	{"1 + 2 : 3 * 4 : xs", "((1 + 2) : ((3 * 4) : xs))"},
+	{"x : xs == ys", "((x : xs) == ys)"},
+	{"a | b & c", "(a | (b & c))"},
+	{"a ^ b | c ^ d", "(((a ^ b) | c) ^ d)"},
+	{"1 << 2 + 3 >> 4", "((1 << 2) + (3 >> 4))"},
+	{"a & b == c | d", "((a & b) == (c | d))"},
+	{"x : a | b", "(x : (a | b))"},
+
+	// Unary operators
+	{"-1 * 2 + 3", "(((-1) * 2) + 3)"},
+	{"1 + 2 * -3 - 4 / (-5 + 6)", "((1 + (2 * (-3))) - (4 / ((-5) + 6)))"},
+	{"!a == b", "((!a) == b)"},
+	{"!a : xs", "(!(a : xs))"},
+	{"!a && b", "((!a) && b)"},
+	{"!!a", "(!(!a))"},
+	{"- -1", "(-(-1))"},
+	{"--1 * 2", "((-(-1)) * 2)"},
+	{"a--b * c", "(a - ((-b) * c))"},
+	{"-x : xs", "((-x) : xs)"},
+	{"~a & b", "((~a) & b)"},
+	{"~-a << ~b", "((~(-a)) << (~b))"},
+
+	// Postfix operators bind tighter than any other operator
+	{"-x.hd * f(y).tl.hd", "((-x.hd) * f(y).tl.hd)"},
+	{"(a + b).fst", "(a + b).fst"},
+
+	// User-defined operators
+	{"xs ++ ys ++ zs", "(xs ++ (ys ++ zs))"},
+	{"x : xs ++ ys", "(x : (xs ++ ys))"},
+	{"a <+> b + c <+> d", "(((a <+> b) + c) <+> d)"},
+	{"a <+> b * c", "(a <+> (b * c))"},
+	{"xs !? 1 + 2", "((xs !? 1) + 2)"},
+	{"-xs !? 1", "(-(xs !? 1))"},
+	{"!!a !? 1", "(!(!(a !? 1)))"},
+	{"a === b && c", "((a === b) && c)"},
+	{"f $ g $ x + 1", "(f $ (g $ (x + 1)))"},
+}
+
+func TestPrecedence(t *testing.T) {
+	for _, test := range precedenceTests {
+		t.Run(test.src, func(t *testing.T) {
+			expr, errors := parseTestExpression(test.src)
+			for _, err := range errors {
+				t.Error(err)
+			}
+			if tree := parenthesize(expr); tree != test.tree {
+				t.Errorf("Parsed %q as %s, expected %s", test.src, tree, test.tree)
+			}
+		})
+	}
+}
+
+func TestPrecedenceNonAssociative(t *testing.T) {
+	for _, src := range []string{"a === b === c", "a === b == c"} {
+		if _, errors := parseTestExpression(src); len(errors) == 0 {
+			t.Errorf("Expected error for chained non-associative operator in %q", src)
+		}
+	}
+}
+
+// parseTestExpression parses src as the value of a return statement, with the test operators declared
+func parseTestExpression(src string) (ast.Expression, scanner.ErrorList) {
+	fileInfo := &token.FileInfo{
+		Filename: "test.spl",
+	}
+
+	p := &Parser{}
+	p.Init(fileInfo, []byte(testFixityDeclarations+"Int main() { return "+src+"; }"))
+	file := p.Parse()
+	errors := p.Errors
+
+	decl, ok := file.Declarations[len(file.Declarations)-1].(*ast.FunctionDeclaration)
+	if !ok || len(decl.Statements) != 1 {
+		return &ast.BadExpression{}, errors
+	}
+	ret, ok := decl.Statements[0].(*ast.ReturnStatement)
+	if !ok {
+		return &ast.BadExpression{}, errors
+	}
+	return ret.Value, errors
+}
+
+// parenthesize prints expr with parentheses around every unary and binary expression, and leaves out parentheses from the
+// source
+func parenthesize(expr ast.Expression) string {
+	return ast.PrintSourceMode(expr, ast.FullParentheses)
+}
+
+func TestFixityDeclarationErrors(t *testing.T) {
+	src := `
+infixl 6 + plus;
+infixr 5 <= lessEquals;
+infix 4 // divide;
+infix 4 /*/ comment;
+infixl 10 <+> add;
+infixr -1 <-> sub;
+infixl 9 !? index;
+`
+	checkErrors(t, src, []string{
+		"test.spl:2:10: cannot declare builtin operator or comment +",
+		"test.spl:3:10: cannot declare builtin operator or comment <=",
+		"test.spl:4:9: cannot declare builtin operator or comment //",
+		"test.spl:5:9: cannot declare builtin operator or comment /*/",
+		"test.spl:6:8: precedence must be between 0 and 9",
+		"test.spl:7:8: expected precedence, got MINUS",
+	})
+}
+
+func TestDeclaredIncrementOperator(t *testing.T) {
+	fileInfo := &token.FileInfo{
+		Filename: "test.spl",
+	}
+
+	p := &Parser{}
+	p.Init(fileInfo, []byte("infixr 5 ++ append;\nVoid main() { xs++; xs = xs ++ ys ++ []; }"))
+	file := p.Parse()
+	for _, err := range p.Errors {
+		t.Error(err)
+	}
+
+	stmts := file.Declarations[1].(*ast.FunctionDeclaration).Statements
+	if len(stmts) != 2 {
+		t.Fatalf("Expected 2 statements, got %d", len(stmts))
+	}
+	if _, ok := stmts[0].(*ast.IncrementStatement); !ok {
+		t.Errorf("Expected xs++ to be an increment statement, got %T", stmts[0])
+	}
+	if tree := parenthesize(stmts[1].(*ast.AssignmentStatement).Value); tree != "(xs ++ (ys ++ []))" {
+		t.Errorf("Parsed xs ++ ys ++ [] as %s", tree)
+	}
+}
@@ -0,0 +1,291 @@
+package parser
+
+import (
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Minnozz/gospl/ast"
+)
+
+// resolver links identifiers to the objects they refer to.  Identifiers that refer to nothing in the file or in
+// ast.Universe (e.g. because they are declared in an imported file) are collected in unresolved.
+type resolver struct {
+	p          *Parser
+	topScope   *ast.Scope
+	unresolved []*ast.Identifier
+}
+
+// resolve resolves the identifiers in file, and sets the scopes and unresolved identifiers of the file and its functions
+func (p *Parser) resolve(file *ast.File) {
+	r := &resolver{
+		p:        p,
+		topScope: ast.NewScope(ast.Universe),
+	}
+	file.Scope = r.topScope
+
+	// Top-level declarations can be used before they are declared
+	for _, decl := range file.Declarations {
+		switch d := decl.(type) {
+		case *ast.VariableDeclaration:
+			r.declarePattern(ast.GlobalVar, d)
+		case *ast.FunctionDeclaration:
+			r.declare(ast.Fun, d.Name, d)
+		case *ast.RecordDeclaration:
+			r.declare(ast.Typ, d.Name, d)
+		case *ast.TypeDeclaration:
+			r.declare(ast.Typ, d.Name, d)
+		case *ast.DataDeclaration:
+			r.declare(ast.Typ, d.Name, d)
+			for _, con := range d.Constructors {
+				r.declare(ast.Con, con.Name, con)
+			}
+		}
+	}
+
+	for _, decl := range file.Declarations {
+		switch d := decl.(type) {
+		case *ast.FixityDeclaration:
+			r.resolveIdentifier(d.Function)
+		case *ast.VariableDeclaration:
+			r.walk(d.Type)
+			r.walk(d.Pattern)
+			r.walk(d.Initializer)
+		case *ast.FunctionDeclaration:
+			r.resolveFunctionDeclaration(d)
+		case *ast.RecordDeclaration:
+			for _, field := range d.Fields {
+				r.walk(field.Type)
+			}
+		case *ast.TypeDeclaration:
+			r.openScope()
+			r.declareTypeParameters(d.TypeParameters, d)
+			r.walk(d.Type)
+			r.closeScope()
+		case *ast.DataDeclaration:
+			r.openScope()
+			r.declareTypeParameters(d.TypeParameters, d)
+			for _, con := range d.Constructors {
+				for _, field := range con.Fields {
+					r.walk(field)
+				}
+			}
+			r.closeScope()
+		}
+	}
+
+	file.Unresolved = r.unresolved
+}
+
+func (r *resolver) openScope() {
+	r.topScope = ast.NewScope(r.topScope)
+}
+
+func (r *resolver) closeScope() {
+	r.topScope = r.topScope.Outer
+}
+
+// declare adds an object for ident to the innermost scope
+func (r *resolver) declare(kind ast.ObjectKind, ident *ast.Identifier, decl ast.Node) {
+	if ident == nil || ident.Name == "" {
+		return
+	}
+	obj := ast.NewObject(kind, ident.Name, decl)
+	insert := r.topScope.Insert
+	if kind == ast.Typ || kind == ast.TypeVar {
+		insert = r.topScope.InsertType
+	}
+	if alt := insert(obj); alt != nil {
+		if r.p.Mode&DeclarationErrors != 0 {
+			r.p.error(ident.Pos(), ident.Name+" redeclared in this scope")
+		}
+		obj = alt
+	}
+	ident.Obj = obj
+}
+
+// declarePattern declares the variables of a variable declaration, including those in a destructuring pattern
+func (r *resolver) declarePattern(kind ast.ObjectKind, decl *ast.VariableDeclaration) {
+	if decl.Name != nil {
+		r.declare(kind, decl.Name, decl)
+	} else {
+		r.declareBindings(kind, decl.Pattern, decl)
+	}
+}
+
+// declareBindings declares the variables bound by pattern.  Identifiers that start with an upper case letter are
+// constructors, which are resolved by walking the pattern afterwards.
+func (r *resolver) declareBindings(kind ast.ObjectKind, pattern ast.Expression, decl ast.Node) {
+	if pattern == nil {
+		return
+	}
+	ast.Inspect(pattern, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Identifier); ok && !isUpper(ident.Name) {
+			r.declare(kind, ident, decl)
+		}
+		return true
+	})
+}
+
+func (r *resolver) declareTypeParameters(params []*ast.Identifier, decl ast.Node) {
+	for _, param := range params {
+		r.declare(ast.TypeVar, param, decl)
+	}
+}
+
+// resolveIdentifier links ident to the variable, function or constructor with its name in the innermost scope that has
+// one
+func (r *resolver) resolveIdentifier(ident *ast.Identifier) {
+	r.resolve(ident, (*ast.Scope).Lookup)
+}
+
+// resolveType links ident to the type or type variable with its name in the innermost scope that has one
+func (r *resolver) resolveType(ident *ast.Identifier) {
+	r.resolve(ident, (*ast.Scope).LookupType)
+}
+
+func (r *resolver) resolve(ident *ast.Identifier, lookup func(*ast.Scope, string) *ast.Object) {
+	if ident == nil || ident.Name == "" || ident.Obj != nil {
+		return
+	}
+	for s := r.topScope; s != nil; s = s.Outer {
+		if obj := lookup(s, ident.Name); obj != nil {
+			ident.Obj = obj
+			return
+		}
+	}
+	r.unresolved = append(r.unresolved, ident)
+}
+
+func (r *resolver) resolveFunctionDeclaration(decl *ast.FunctionDeclaration) {
+	r.openScope()
+	decl.Scope = r.topScope
+
+	// Type variables are declared by their first use in the signature.  Type classes are not declared anywhere, so the
+	// names of the constraints are not resolved.
+	if decl.Context != nil {
+		for _, constraint := range decl.Context.Constraints {
+			if named, ok := constraint.(*ast.NamedType); ok {
+				for _, arg := range named.Arguments {
+					r.walkSignatureType(arg, decl)
+				}
+			}
+		}
+	}
+	r.walkSignatureType(decl.ReturnType, decl)
+	if decl.Parameters != nil {
+		for _, param := range decl.Parameters.Parameters {
+			r.walkSignatureType(param.Type, decl)
+			r.declare(ast.Param, param.Name, param)
+		}
+	}
+
+	for _, varDecl := range decl.Variables {
+		r.walk(varDecl.Type)
+		r.walk(varDecl.Initializer)
+		r.declarePattern(ast.LocalVar, varDecl)
+		r.walk(varDecl.Pattern)
+	}
+	for _, stmt := range decl.Statements {
+		r.walk(stmt)
+	}
+
+	r.closeScope()
+}
+
+// walkSignatureType resolves the names in a type in the signature of decl, and declares the type variables that are
+// not declared yet
+func (r *resolver) walkSignatureType(t ast.Type, decl *ast.FunctionDeclaration) {
+	if t == nil {
+		return
+	}
+	ast.Inspect(t, func(n ast.Node) bool {
+		if tv, ok := n.(*ast.TypeVariable); ok && r.topScope.LookupType(tv.Name.Name) == nil {
+			r.declare(ast.TypeVar, tv.Name, decl)
+		}
+		return true
+	})
+	r.walk(t)
+}
+
+// walk resolves the identifiers in n, opening scopes for the constructs that bind variables
+func (r *resolver) walk(n ast.Node) {
+	if n == nil {
+		return
+	}
+	ast.Inspect(n, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.Identifier:
+			r.resolveIdentifier(n)
+		case *ast.NamedType:
+			r.resolveType(n.Name)
+			for _, arg := range n.Arguments {
+				r.walk(arg)
+			}
+			return false
+		case *ast.BasicType:
+			r.resolveType(n.Name)
+			return false
+		case *ast.TypeVariable:
+			r.resolveType(n.Name)
+			return false
+		case *ast.RecordExpression:
+			r.resolveType(n.Name)
+			for _, value := range n.Values {
+				r.walk(value)
+			}
+			return false
+		case *ast.FieldExpression:
+			// The field name is not resolved
+			r.walk(n.Expression)
+			return false
+		case *ast.LambdaExpression:
+			r.openScope()
+			for _, param := range n.Parameters {
+				r.declare(ast.Param, param, n)
+			}
+			r.walk(n.Body)
+			r.closeScope()
+			return false
+		case *ast.ComprehensionExpression:
+			// Each generator binds variables in the qualifiers after it and in the value
+			for _, qualifier := range n.Qualifiers {
+				if gen, ok := qualifier.(*ast.ComprehensionGenerator); ok {
+					r.walk(gen.List)
+					r.openScope()
+					r.declareBindings(ast.LocalVar, gen.Pattern, gen)
+					r.walk(gen.Pattern)
+				} else {
+					r.walk(qualifier)
+				}
+			}
+			r.walk(n.Value)
+			for _, qualifier := range n.Qualifiers {
+				if _, ok := qualifier.(*ast.ComprehensionGenerator); ok {
+					r.closeScope()
+				}
+			}
+			return false
+		case *ast.ForStatement:
+			r.walk(n.Value)
+			r.walk(n.High)
+			r.openScope()
+			r.declare(ast.LocalVar, n.Variable, n)
+			r.walk(n.Body)
+			r.closeScope()
+			return false
+		case *ast.MatchCase:
+			r.openScope()
+			r.declareBindings(ast.LocalVar, n.Pattern, n)
+			r.walk(n.Pattern)
+			r.walk(n.Body)
+			r.closeScope()
+			return false
+		}
+		return true
+	})
+}
+
+func isUpper(name string) bool {
+	ch, _ := utf8.DecodeRuneInString(name)
+	return unicode.IsUpper(ch)
+}
@@ -0,0 +1,181 @@
+package parser
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"testing"
+
+	"github.com/Minnozz/gospl/ast"
+	"github.com/Minnozz/gospl/token"
+)
+
+const resolverTestSource = `data Pair t = Pair t t;
+Int limit = 10;
+
+t
+first((Pair t) p, Int n) {
+	Int total = n + limit;
+	var f = \x -> x + total;
+	[Int] xs = [x * y | x <- [1 .. n], y <- [x .. n], x < y];
+	match(p) {
+		Pair(x, y) -> return x;
+	}
+	for (i in [1 .. n]) {
+		total = total + i;
+	}
+	print(missing(f(total)));
+	return first(p, n);
+}
+`
+
+func TestResolver(t *testing.T) {
+	fileInfo := &token.FileInfo{
+		Filename: "test.spl",
+	}
+
+	p := &Parser{}
+	p.Init(fileInfo, []byte(resolverTestSource))
+	file := p.Parse()
+	for _, err := range p.Errors {
+		t.Error(err)
+	}
+
+	// Identifiers by position, and the kind and declaration position of the objects they refer to
+	expected := map[string]string{
+		"1:6":   "type 1:6",
+		"1:11":  "type var 1:11",
+		"1:15":  "constructor 1:15",
+		"1:20":  "type var 1:11",
+		"1:22":  "type var 1:11",
+		"2:1":   "builtin",
+		"2:5":   "global var 2:5",
+		"4:1":   "type var 4:1",
+		"5:1":   "func 5:1",
+		"5:8":   "type 1:6",
+		"5:13":  "type var 4:1",
+		"5:16":  "param 5:16",
+		"5:19":  "builtin",
+		"5:23":  "param 5:23",
+		"6:2":   "builtin",
+		"6:6":   "local var 6:6",
+		"6:14":  "param 5:23",
+		"6:18":  "global var 2:5",
+		"7:6":   "local var 7:6",
+		"7:11":  "param 7:11",
+		"7:16":  "param 7:11",
+		"7:20":  "local var 6:6",
+		"8:3":   "builtin",
+		"8:8":   "local var 8:8",
+		"8:14":  "local var 8:22",
+		"8:18":  "local var 8:37",
+		"8:22":  "local var 8:22",
+		"8:33":  "param 5:23",
+		"8:37":  "local var 8:37",
+		"8:43":  "local var 8:22",
+		"8:48":  "param 5:23",
+		"8:52":  "local var 8:22",
+		"8:56":  "local var 8:37",
+		"9:8":   "param 5:16",
+		"10:3":  "constructor 1:15",
+		"10:8":  "local var 10:8",
+		"10:11": "local var 10:11",
+		"10:24": "local var 10:8",
+		"12:7":  "local var 12:7",
+		"12:18": "param 5:23",
+		"13:3":  "local var 6:6",
+		"13:11": "local var 6:6",
+		"13:19": "local var 12:7",
+		"15:2":  "builtin",
+		"15:8":  "unresolved",
+		"15:16": "local var 7:6",
+		"15:18": "local var 6:6",
+		"16:9":  "func 5:1",
+		"16:15": "param 5:16",
+		"16:18": "param 5:23",
+	}
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		ident, ok := n.(*ast.Identifier)
+		if !ok {
+			return true
+		}
+		position := fileInfo.Position(ident.Pos())
+		pos := fmt.Sprintf("%d:%d", position.Line, position.Column)
+
+		got := "unresolved"
+		if obj := ident.Obj; obj != nil {
+			got = obj.Kind.String()
+			if declPos := obj.Pos(); declPos != token.NoPos {
+				declPosition := fileInfo.Position(declPos)
+				got += fmt.Sprintf(" %d:%d", declPosition.Line, declPosition.Column)
+			}
+		}
+		if want, ok := expected[pos]; !ok {
+			t.Errorf("Unexpected identifier %s at %s (%s)", ident.Name, pos, got)
+		} else if got != want {
+			t.Errorf("Identifier %s at %s: got %s, expected %s", ident.Name, pos, got, want)
+		}
+		delete(expected, pos)
+		return true
+	})
+	for pos := range expected {
+		t.Errorf("No identifier at %s", pos)
+	}
+
+	if len(file.Unresolved) != 1 || file.Unresolved[0].Name != "missing" {
+		t.Errorf("Expected only missing to be unresolved, got %v", file.Unresolved)
+	}
+	if decl := file.Declarations[2].(*ast.FunctionDeclaration); decl.Scope == nil || decl.Scope.Outer != file.Scope {
+		t.Errorf("Scope of function is not nested in the scope of the file")
+	}
+}
+
+func TestResolverRedeclared(t *testing.T) {
+	src := "Int x = 1;\nBool x = True;\nVoid f(Int a, Int a) { Int b = a; }\n"
+
+	checkModeErrors(t, DeclarationErrors, src, []string{
+		"test.spl:2:6: x redeclared in this scope",
+		"test.spl:3:19: a redeclared in this scope",
+	})
+	checkErrors(t, src, nil)
+}
+
+func TestResolverValid(t *testing.T) {
+	// Names that are not declared in the test programs, in alphabetical order
+	undeclared := map[string][]string{
+		"test09.spl": {"random"},
+		"test36.spl": {"length"},
+		"test42.spl": {"f"},
+	}
+
+	tests, err := ioutil.ReadDir("../testdata/valid")
+	if err != nil {
+		t.Fatalf("Error reading test directory: %v", err)
+	}
+
+	for _, test := range tests {
+		name := test.Name()
+		src, err := ioutil.ReadFile("../testdata/valid/" + name)
+		if err != nil {
+			t.Fatalf("Error reading test %s: %v", name, err)
+		}
+
+		p := &Parser{}
+		p.Init(&token.FileInfo{Filename: name}, src)
+		file := p.Parse()
+
+		var unresolved []string
+		seen := make(map[string]bool)
+		for _, ident := range file.Unresolved {
+			if !seen[ident.Name] {
+				unresolved = append(unresolved, ident.Name)
+				seen[ident.Name] = true
+			}
+		}
+		sort.Strings(unresolved)
+		if got, want := fmt.Sprint(unresolved), fmt.Sprint(undeclared[name]); got != want {
+			t.Errorf("%s: unresolved identifiers %s, expected %s", name, got, want)
+		}
+	}
+}
@@ -11,3 +11,11 @@ func isDigit(c byte) bool {
 func isWord(c byte) bool {
 	return isAlpha(c) || isDigit(c) || c == '_'
 }
+
+func isOperator(c byte) bool {
+	switch c {
+	case '!', '#', '$', '%', '&', '*', '+', '.', '/', ':', '<', '=', '>', '?', '@', '^', '|', '-', '~':
+		return true
+	}
+	return false
+}
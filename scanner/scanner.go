@@ -1,6 +1,7 @@
 package scanner
 
 import (
+	"bytes"
 	"fmt"
 
 	"github.com/Minnozz/gospl/token"
@@ -16,6 +17,9 @@ type Scanner struct {
 	ch     byte
 	offset int
 
+	// User-defined operators
+	operators []string
+
 	ErrorCount int
 }
 
@@ -49,46 +53,117 @@ func (s *Scanner) Scan() (pos token.Pos, tok token.Token, lit string) {
 	case isDigit(ch):
 		tok = token.INTEGER
 		lit = s.scanNumber()
+	case s.declaredOperator() != "":
+		tok = token.OPERATOR
+		lit = s.declaredOperator()
+		for range lit {
+			s.next()
+		}
 	default:
 		// First advance to the next character
 		s.next()
 		// Then look at the current(/previous) character
 		switch ch {
 		case '+':
-			tok = token.PLUS
+			switch s.ch {
+			case '+':
+				s.next()
+				tok = token.INCREMENT
+			case '=':
+				s.next()
+				tok = token.PLUS_IS
+			default:
+				tok = token.PLUS
+			}
 		case '-':
-			tok = token.MINUS
-			// Could also be a negative token.INTEGER according to the grammar, but we scan
-			// those as token.MINUS + token.INTEGER.
+			switch s.ch {
+			case '>':
+				s.next()
+				tok = token.ARROW
+			case '-':
+				// "--1" is scanned as a token.DECREMENT; the parser splits it into two token.MINUS inside expressions
+				s.next()
+				tok = token.DECREMENT
+			case '=':
+				s.next()
+				tok = token.MINUS_IS
+			default:
+				tok = token.MINUS
+				// Could also be a negative token.INTEGER according to the grammar, but we scan
+				// those as token.MINUS + token.INTEGER.
+			}
 		case '*':
-			tok = token.MULTIPLY
+			tok = s.try('=', token.MULTIPLY_IS, token.MULTIPLY)
 		case '/':
 			if s.ch == '/' || s.ch == '*' {
 				tok = token.COMMENT
 				lit = s.scanComment()
 			} else {
-				tok = token.DIVIDE
+				tok = s.try('=', token.DIVIDE_IS, token.DIVIDE)
 			}
 		case '%':
-			tok = token.MODULO
+			tok = s.try('=', token.MODULO_IS, token.MODULO)
 		case '&':
-			tok = s.expect('&', token.AND)
+			tok = s.try('&', token.AND, token.AMPERSAND)
+		case '^':
+			tok = token.CARET
+		case '~':
+			tok = token.TILDE
 		case '|':
-			tok = s.expect('|', token.OR)
+			tok = s.try('|', token.OR, token.BAR)
 		case '=':
-			tok = s.try('=', token.EQUALS, token.IS)
+			switch s.ch {
+			case '=':
+				s.next()
+				tok = token.EQUALS
+			case '>':
+				s.next()
+				tok = token.DOUBLE_ARROW
+			default:
+				tok = token.IS
+			}
 		case '<':
-			tok = s.try('=', token.LESS_THAN_EQUALS, token.LESS_THAN)
+			switch s.ch {
+			case '=':
+				s.next()
+				tok = token.LESS_THAN_EQUALS
+			case '-':
+				// "a<-1" is scanned as a token.LEFT_ARROW; the parser splits it into token.LESS_THAN and token.MINUS outside
+				// comprehension qualifiers
+				s.next()
+				tok = token.LEFT_ARROW
+			case '<':
+				s.next()
+				tok = token.SHIFT_LEFT
+			default:
+				tok = token.LESS_THAN
+			}
 		case '>':
-			tok = s.try('=', token.GREATER_THAN_EQUALS, token.GREATER_THAN)
+			switch s.ch {
+			case '=':
+				s.next()
+				tok = token.GREATER_THAN_EQUALS
+			case '>':
+				s.next()
+				tok = token.SHIFT_RIGHT
+			default:
+				tok = token.GREATER_THAN
+			}
 		case '!':
 			tok = s.try('=', token.NOT_EQUALS, token.NOT)
+		case '\\':
+			tok = token.BACKSLASH
+		case '"':
+			tok = token.STRING
+			lit = s.scanString()
 		case ',':
 			tok = token.COMMA
 		case ';':
 			tok = token.SEMICOLON
 		case ':':
 			tok = token.COLON
+		case '.':
+			tok = s.try('.', token.RANGE, token.PERIOD)
 		case '(':
 			tok = token.ROUND_BRACKET_OPEN
 		case ')':
@@ -113,6 +188,50 @@ func (s *Scanner) Scan() (pos token.Pos, tok token.Token, lit string) {
 	return pos, tok, lit
 }
 
+// Lookahead returns a copy of s that scans the same tokens as s, without reporting errors or adding lines to the file info
+// of s
+func (s *Scanner) Lookahead() *Scanner {
+	lookahead := *s
+	lookahead.fileInfo = &token.FileInfo{
+		Filename: s.fileInfo.Filename,
+	}
+	lookahead.errorHandler = nil
+	return &lookahead
+}
+
+// DeclareOperator makes Scan() return token.OPERATOR for op, instead of the tokens it would otherwise consist of
+func (s *Scanner) DeclareOperator(op string) {
+	s.operators = append(s.operators, op)
+}
+
+// ScanOperator scans a sequence of operator characters that do not need to form a declared operator
+func (s *Scanner) ScanOperator() (pos token.Pos, tok token.Token, lit string) {
+	s.skipWhitespace()
+
+	pos = s.fileInfo.Pos(s.offset)
+	start := s.offset
+	for s.offset < len(s.src) && isOperator(s.ch) {
+		s.next()
+	}
+	if s.offset == start {
+		s.error(s.offset, fmt.Sprintf("expected operator, got %+q", s.ch))
+		return pos, token.INVALID, ""
+	}
+
+	return pos, token.OPERATOR, string(s.src[start:s.offset])
+}
+
+// declaredOperator returns the longest declared operator at the current offset, if any
+func (s *Scanner) declaredOperator() string {
+	longest := ""
+	for _, op := range s.operators {
+		if len(op) > len(longest) && bytes.HasPrefix(s.src[s.offset:], []byte(op)) {
+			longest = op
+		}
+	}
+	return longest
+}
+
 func (s *Scanner) error(offset int, msg string) {
 	if s.errorHandler != nil {
 		s.errorHandler(s.fileInfo.Position(s.fileInfo.Pos(offset)), msg)
@@ -183,13 +302,24 @@ ok:
 	return string(s.src[start:s.offset])
 }
 
-func (s *Scanner) expect(ch byte, match token.Token) token.Token {
-	if s.ch == ch {
+func (s *Scanner) scanString() string {
+	// Opening '"' has been consumed; s.ch is the next character
+	start := s.offset - 1
+
+	for s.ch != '"' {
+		if s.ch == '\n' || s.offset >= len(s.src) {
+			s.error(start, "string literal not terminated")
+			return string(s.src[start:s.offset])
+		}
+		if s.ch == '\\' {
+			// Skip escaped character
+			s.next()
+		}
 		s.next()
-		return match
 	}
-	s.error(s.offset, fmt.Sprintf("expected %+q to scan %v, got %+q", ch, match, s.ch))
-	return token.INVALID
+	s.next()
+
+	return string(s.src[start:s.offset])
 }
 
 func (s *Scanner) try(ch byte, match, mismatch token.Token) token.Token {
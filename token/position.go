@@ -25,6 +25,19 @@ func (f *FileInfo) Pos(offset int) Pos {
 	return Pos(offset + 1)
 }
 
+// LinePos returns the position of a 1-based line and column, or NoPos if the line has not been scanned
+func (f *FileInfo) LinePos(line, column int) Pos {
+	if line < 1 || line > len(f.newlines)+1 || column < 1 {
+		return NoPos
+	}
+
+	offset := column - 1
+	if line > 1 {
+		offset += f.newlines[line-2] + 1
+	}
+	return f.Pos(offset)
+}
+
 func (f *FileInfo) Position(pos Pos) Position {
 	if pos == NoPos {
 		return Position{}
@@ -0,0 +1,74 @@
+package token
+
+type Precedence int
+
+// Precedence levels of the builtin operators, from lowest to highest.  User-defined operators are declared at a level from
+// MinPrecedence to MaxPrecedence, and bind like builtin operators at the same level.
+const (
+	MinPrecedence        Precedence = 0
+	binaryBoolean        Precedence = 2 // &&, ||
+	binaryComparison     Precedence = 3 // ==, !=, <, >, <=, >=
+	unaryNot             Precedence = 4 // !
+	binaryColon          Precedence = 5 // :
+	binaryAddition       Precedence = 6 // +, -, |, ^
+	binaryMultiplication Precedence = 7 // *, /, %, &, <<, >>
+	unaryMinus           Precedence = 8 // -, ~
+	MaxPrecedence        Precedence = 9
+)
+
+type Associativity int
+
+const (
+	LeftAssociative Associativity = iota
+	RightAssociative
+	NonAssociative
+)
+
+type Fixity struct {
+	Precedence    Precedence
+	Associativity Associativity
+}
+
+var unaryOperators = map[Token]Fixity{
+	NOT:   {unaryNot, RightAssociative},
+	MINUS: {unaryMinus, RightAssociative},
+	TILDE: {unaryMinus, RightAssociative},
+}
+
+var binaryOperators = map[Token]Fixity{
+	AND: {binaryBoolean, LeftAssociative},
+	OR:  {binaryBoolean, LeftAssociative},
+
+	EQUALS:              {binaryComparison, LeftAssociative},
+	NOT_EQUALS:          {binaryComparison, LeftAssociative},
+	LESS_THAN:           {binaryComparison, LeftAssociative},
+	GREATER_THAN:        {binaryComparison, LeftAssociative},
+	LESS_THAN_EQUALS:    {binaryComparison, LeftAssociative},
+	GREATER_THAN_EQUALS: {binaryComparison, LeftAssociative},
+
+	COLON: {binaryColon, RightAssociative},
+
+	PLUS:  {binaryAddition, LeftAssociative},
+	MINUS: {binaryAddition, LeftAssociative},
+	BAR:   {binaryAddition, LeftAssociative},
+	CARET: {binaryAddition, LeftAssociative},
+
+	MULTIPLY:    {binaryMultiplication, LeftAssociative},
+	DIVIDE:      {binaryMultiplication, LeftAssociative},
+	MODULO:      {binaryMultiplication, LeftAssociative},
+	AMPERSAND:   {binaryMultiplication, LeftAssociative},
+	SHIFT_LEFT:  {binaryMultiplication, LeftAssociative},
+	SHIFT_RIGHT: {binaryMultiplication, LeftAssociative},
+}
+
+// UnaryFixity returns the fixity of a builtin unary operator
+func UnaryFixity(op Token) (Fixity, bool) {
+	fixity, ok := unaryOperators[op]
+	return fixity, ok
+}
+
+// BinaryFixity returns the fixity of a builtin binary operator.  User-defined operators (OPERATOR) are not builtin.
+func BinaryFixity(op Token) (Fixity, bool) {
+	fixity, ok := binaryOperators[op]
+	return fixity, ok
+}
@@ -11,6 +11,8 @@ const (
 	// Literals
 	IDENTIFIER // Void
 	INTEGER    // 12345
+	STRING     // "lists"
+	OPERATOR   // <+>
 
 	// Operators and delimiters
 	PLUS     // +
@@ -22,19 +24,41 @@ const (
 	AND // &&
 	OR  // ||
 
+	AMPERSAND   // &
+	CARET       // ^
+	TILDE       // ~
+	SHIFT_LEFT  // <<
+	SHIFT_RIGHT // >>
+
 	EQUALS       // ==
 	LESS_THAN    // <
 	GREATER_THAN // >
 	IS           // =
 	NOT          // !
 
+	PLUS_IS     // +=
+	MINUS_IS    // -=
+	MULTIPLY_IS // *=
+	DIVIDE_IS   // /=
+	MODULO_IS   // %=
+	INCREMENT   // ++
+	DECREMENT   // --
+
 	NOT_EQUALS          // !=
 	LESS_THAN_EQUALS    // <=
 	GREATER_THAN_EQUALS // >=
 
+	BAR          // |
+	ARROW        // ->
+	DOUBLE_ARROW // =>
+	LEFT_ARROW   // <-
+	BACKSLASH    // \
+
 	COMMA     // ,
 	SEMICOLON // ;
 	COLON     // :
+	PERIOD    // .
+	RANGE     // ..
 
 	ROUND_BRACKET_OPEN   // (
 	ROUND_BRACKET_CLOSE  // )
@@ -46,20 +70,46 @@ const (
 	EMPTY_LIST // []
 
 	// Keywords
-	IF     // if
-	ELSE   // else
-	WHILE  // while
-	RETURN // return
+	IF       // if
+	ELSE     // else
+	WHILE    // while
+	FOR      // for
+	IN       // in
+	BREAK    // break
+	CONTINUE // continue
+	RETURN   // return
+	RECORD   // record
+	DATA     // data
+	MATCH    // match
+	IMPORT   // import
+	INFIX    // infix
+	INFIXL   // infixl
+	INFIXR   // infixr
+	VAR      // var
+	TYPE     // type
 )
 
 //go:generate stringer -type=Token
 
 // Words that are keywords
 var keywords = map[string]Token{
-	"if":     IF,
-	"else":   ELSE,
-	"while":  WHILE,
-	"return": RETURN,
+	"if":       IF,
+	"else":     ELSE,
+	"while":    WHILE,
+	"for":      FOR,
+	"in":       IN,
+	"break":    BREAK,
+	"continue": CONTINUE,
+	"return":   RETURN,
+	"record":   RECORD,
+	"data":     DATA,
+	"match":    MATCH,
+	"import":   IMPORT,
+	"infix":    INFIX,
+	"infixl":   INFIXL,
+	"infixr":   INFIXR,
+	"var":      VAR,
+	"type":     TYPE,
 }
 
 // LookupWord returns the Token and literal for a scanned word
@@ -82,19 +132,41 @@ var printStrings = map[Token]string{
 	AND: "&&",
 	OR:  "||",
 
+	AMPERSAND:   "&",
+	CARET:       "^",
+	TILDE:       "~",
+	SHIFT_LEFT:  "<<",
+	SHIFT_RIGHT: ">>",
+
 	EQUALS:       "==",
 	LESS_THAN:    "<",
 	GREATER_THAN: ">",
 	IS:           "=",
 	NOT:          "!",
 
+	PLUS_IS:     "+=",
+	MINUS_IS:    "-=",
+	MULTIPLY_IS: "*=",
+	DIVIDE_IS:   "/=",
+	MODULO_IS:   "%=",
+	INCREMENT:   "++",
+	DECREMENT:   "--",
+
 	NOT_EQUALS:          "!=",
 	LESS_THAN_EQUALS:    "<=",
 	GREATER_THAN_EQUALS: ">=",
 
+	BAR:          "|",
+	ARROW:        "->",
+	DOUBLE_ARROW: "=>",
+	LEFT_ARROW:   "<-",
+	BACKSLASH:    "\\",
+
 	COMMA:     ",",
 	SEMICOLON: ";",
 	COLON:     ":",
+	PERIOD:    ".",
+	RANGE:     "..",
 
 	ROUND_BRACKET_OPEN:   "(",
 	ROUND_BRACKET_CLOSE:  ")",
@@ -105,10 +177,33 @@ var printStrings = map[Token]string{
 
 	EMPTY_LIST: "[]",
 
-	IF:     "if",
-	ELSE:   "else",
-	WHILE:  "while",
-	RETURN: "return",
+	IF:       "if",
+	ELSE:     "else",
+	WHILE:    "while",
+	FOR:      "for",
+	IN:       "in",
+	BREAK:    "break",
+	CONTINUE: "continue",
+	RETURN:   "return",
+	RECORD:   "record",
+	DATA:     "data",
+	MATCH:    "match",
+	IMPORT:   "import",
+	INFIX:    "infix",
+	INFIXL:   "infixl",
+	INFIXR:   "infixr",
+	VAR:      "var",
+	TYPE:     "type",
+}
+
+// LookupSymbol returns the operator or delimiter Token that is written as symbol, or INVALID if there is none
+func LookupSymbol(symbol string) Token {
+	for tok, s := range printStrings {
+		if s == symbol {
+			return tok
+		}
+	}
+	return INVALID
 }
 
 func (t Token) Print() string {
@@ -4,9 +4,9 @@ package token
 
 import "strconv"
 
-const _Token_name = "INVALIDEOFCOMMENTIDENTIFIERINTEGERPLUSMINUSMULTIPLYDIVIDEMODULOANDOREQUALSLESS_THANGREATER_THANISNOTNOT_EQUALSLESS_THAN_EQUALSGREATER_THAN_EQUALSCOMMASEMICOLONCOLONROUND_BRACKET_OPENROUND_BRACKET_CLOSECURLY_BRACKET_OPENCURLY_BRACKET_CLOSESQUARE_BRACKET_OPENSQUARE_BRACKET_CLOSEEMPTY_LISTIFELSEWHILERETURN"
+const _Token_name = "INVALIDEOFCOMMENTIDENTIFIERINTEGERSTRINGOPERATORPLUSMINUSMULTIPLYDIVIDEMODULOANDORAMPERSANDCARETTILDESHIFT_LEFTSHIFT_RIGHTEQUALSLESS_THANGREATER_THANISNOTPLUS_ISMINUS_ISMULTIPLY_ISDIVIDE_ISMODULO_ISINCREMENTDECREMENTNOT_EQUALSLESS_THAN_EQUALSGREATER_THAN_EQUALSBARARROWDOUBLE_ARROWLEFT_ARROWBACKSLASHCOMMASEMICOLONCOLONPERIODRANGEROUND_BRACKET_OPENROUND_BRACKET_CLOSECURLY_BRACKET_OPENCURLY_BRACKET_CLOSESQUARE_BRACKET_OPENSQUARE_BRACKET_CLOSEEMPTY_LISTIFELSEWHILEFORINBREAKCONTINUERETURNRECORDDATAMATCHIMPORTINFIXINFIXLINFIXRVARTYPE"
 
-var _Token_index = [...]uint16{0, 7, 10, 17, 27, 34, 38, 43, 51, 57, 63, 66, 68, 74, 83, 95, 97, 100, 110, 126, 145, 150, 159, 164, 182, 201, 219, 238, 257, 277, 287, 289, 293, 298, 304}
+var _Token_index = [...]uint16{0, 7, 10, 17, 27, 34, 40, 48, 52, 57, 65, 71, 77, 80, 82, 91, 96, 101, 111, 122, 128, 137, 149, 151, 154, 161, 169, 180, 189, 198, 207, 216, 226, 242, 261, 264, 269, 281, 291, 300, 305, 314, 319, 325, 330, 348, 367, 385, 404, 423, 443, 453, 455, 459, 464, 467, 469, 474, 482, 488, 494, 498, 503, 509, 514, 520, 526, 529, 533}
 
 func (i Token) String() string {
 	if i < 0 || i >= Token(len(_Token_index)-1) {